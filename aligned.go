@@ -0,0 +1,95 @@
+package sego
+
+import "unicode/utf8"
+
+// AlignedSegment 以字符位置表示的分词，见SegmentAligned
+type AlignedSegment struct {
+	// 分词在文本中对应的字符串
+	Word string
+
+	// 分词的词性
+	Pos string
+
+	// 分词在文本中的起始字符位置
+	RuneStart int
+
+	// 分词在文本中的结束字符位置（不包括该位置）
+	RuneEnd int
+}
+
+// SegmentAligned 对文本分词，分词的起止位置按Unicode字符而不是字节计算
+//
+// 便于Python等按字符索引字符串的语言使用：把文本按字符切片，[RuneStart:RuneEnd]
+// 即为Word。Word取自文本本身，与Token.Text()不同，保留了英文字元之间原有的空白。
+// 与Segment一样，设置了Unicode规范化时位置和Word仍对应规范化之前的输入。
+func (seg *Segmenter) SegmentAligned(bytes []byte) []AlignedSegment {
+	segments := seg.appendAllSegments(nil, bytes, false, false)
+	aligned := make([]AlignedSegment, 0, len(segments))
+
+	// 分词按起始位置排列，逐段累计字符数。规范化时一个原字符可能分为几个分词，这些
+	// 分词都对应整个原字符，起始位置可能在上一个分词的结束位置之前
+	position, runes := 0, 0
+	for _, segment := range segments {
+		if segment.start >= position {
+			runes += utf8.RuneCount(bytes[position:segment.start])
+		} else {
+			runes -= utf8.RuneCount(bytes[segment.start:position])
+		}
+		numRunes := utf8.RuneCount(bytes[segment.start:segment.end])
+		aligned = append(aligned, AlignedSegment{
+			Word:      string(bytes[segment.start:segment.end]),
+			Pos:       segment.token.pos,
+			RuneStart: runes,
+			RuneEnd:   runes + numRunes,
+		})
+		position, runes = segment.end, runes+numRunes
+	}
+	return aligned
+}
+
+// SegmentRune 以字符位置表示的分词，见SegmentRunes
+type SegmentRune struct {
+	// 分词在输入中的起始字符位置
+	Start int
+
+	// 分词在输入中的结束字符位置（不包括该位置）
+	End int
+
+	// 分词信息
+	Token *Token
+
+	// 是否为停用词，仅在StopMark模式下为true
+	Stop bool
+}
+
+// SegmentRunes 对字符数组分词，分词的起止位置为字符数组的下标
+//
+// 结果与对同一文本调用Segment相同，只是位置按字符计算，runes[Start:End]即为分词
+// 对应的文本。设置了Unicode规范化时位置仍对应输入的字符。无效的字符（比如单独的
+// 代理项）按U+FFFD处理。
+func (seg *Segmenter) SegmentRunes(runes []rune) []SegmentRune {
+	// 编码为UTF8，同时记录每个字符起始字节对应的字符位置
+	bytes := make([]byte, 0, len(runes)*utf8.UTFMax)
+	runeAt := make([]int, 0, len(runes)*utf8.UTFMax+1)
+	var buf [utf8.UTFMax]byte
+	for i, r := range runes {
+		n := utf8.EncodeRune(buf[:], r)
+		bytes = append(bytes, buf[:n]...)
+		for j := 0; j < n; j++ {
+			runeAt = append(runeAt, i)
+		}
+	}
+	runeAt = append(runeAt, len(runes))
+
+	segments := seg.Segment(bytes)
+	output := make([]SegmentRune, len(segments))
+	for i, segment := range segments {
+		output[i] = SegmentRune{
+			Start: runeAt[segment.start],
+			End:   runeAt[segment.end],
+			Token: segment.token,
+			Stop:  segment.stop,
+		}
+	}
+	return output
+}
@@ -0,0 +1,63 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSegmentAligned(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	text := []rune("人口：hello  world，中国")
+	var output string
+	for _, segment := range seg.SegmentAligned([]byte(string(text))) {
+		expect(t, string(text[segment.RuneStart:segment.RuneEnd]), segment.Word)
+		output += fmt.Sprintf("%s/%s(%d,%d) ", segment.Word, segment.Pos, segment.RuneStart, segment.RuneEnd)
+	}
+	expect(t, "人口/p12(0,2) ：/x(2,3) hello  world/p1(3,15) ，/x(15,16) 中国/(16,18) ", output)
+
+	// 删除的停用词不影响之后分词的位置
+	seg.SetStopWords([]string{"hello world"})
+	segments := seg.SegmentAligned([]byte(string(text)))
+	expect(t, "4", len(segments))
+	expect(t, "16", segments[3].RuneStart)
+
+	expect(t, "0", len(seg.SegmentAligned(nil)))
+
+	// 规范化改变了文本长度时，位置和Word仍对应输入
+	seg.SetStopWords(nil)
+	seg.SetNormalization(NormNFKC)
+	text = []rune("（人口）ｈｅｌｌｏ中国")
+	output = ""
+	for _, segment := range seg.SegmentAligned([]byte(string(text))) {
+		expect(t, string(text[segment.RuneStart:segment.RuneEnd]), segment.Word)
+		output += fmt.Sprintf("%s/%s(%d,%d) ", segment.Word, segment.Pos, segment.RuneStart, segment.RuneEnd)
+	}
+	expect(t, "（/x(0,1) 人口/p12(1,3) ）/x(3,4) ｈｅｌｌｏ/p2(4,9) 中国/(9,11) ", output)
+
+	// 一个原字符分为几个分词时位置不会出错
+	for _, segment := range seg.SegmentAligned([]byte("中ﬁ国")) {
+		expect(t, string([]rune("中ﬁ国")[segment.RuneStart:segment.RuneEnd]), segment.Word)
+	}
+}
+
+func TestSegmentRunes(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	text := []rune("人口：hello  world，中国")
+	var output string
+	for _, segment := range seg.SegmentRunes(text) {
+		output += fmt.Sprintf("%s/%s(%d,%d) ", string(text[segment.Start:segment.End]),
+			segment.Token.Pos(), segment.Start, segment.End)
+	}
+	expect(t, "人口/p12(0,2) ：/x(2,3) hello  world/p1(3,15) ，/x(15,16) 中国/(16,18) ", output)
+
+	// 无效的字符按U+FFFD处理，不影响之后分词的位置
+	segments := seg.SegmentRunes([]rune{0xd800, '中', '国'})
+	expect(t, "2", len(segments))
+	expect(t, "1 3", fmt.Sprint(segments[1].Start, segments[1].End))
+
+	expect(t, "0", len(seg.SegmentRunes(nil)))
+}
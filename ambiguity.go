@@ -0,0 +1,124 @@
+package sego
+
+// AmbiguityReport 正向和逆向最大匹配的比较结果，见Segmenter.AmbiguityReport
+type AmbiguityReport struct {
+	// 正向最大匹配的分词
+	Forward []Segment
+
+	// 逆向最大匹配的分词
+	Backward []Segment
+
+	// 两种匹配结果不一致的片段，按在文本中的位置排列
+	Ambiguities []Ambiguity
+}
+
+// Ambiguity 正向和逆向最大匹配划分不同的一段文本
+type Ambiguity struct {
+	// 片段在文本中的起始字节位置
+	Start int
+
+	// 片段在文本中的结束字节位置（不包括该位置）
+	End int
+
+	// 正向最大匹配对该片段的划分
+	Forward []string
+
+	// 逆向最大匹配对该片段的划分
+	Backward []string
+}
+
+// AmbiguityReport 分别用正向最大匹配和逆向最大匹配对文本分词，并找出两者不一致的片段
+//
+// 最大匹配每次取词典中最长的分词，不考虑词频，词典中没有的字元单独成为分词。两种
+// 匹配结果不一致的片段通常存在交集型歧义，比如"中国有"可以划分为"中国/有"和
+// "中/国有"，可以用来估计语料的歧义程度。分词的起止位置与Segment相同，是在规范化
+// 之前的原文本中的位置，结果中保留停用词。
+func (seg *Segmenter) AmbiguityReport(bytes []byte) AmbiguityReport {
+	normalized, normOffsets := seg.normalizeWithOffsets(bytes)
+	text, offsets := splitWords(normalized, seg.split, true, nil, nil)
+	report := AmbiguityReport{}
+	if len(text) == 0 || seg.dict == nil {
+		return report
+	}
+
+	// 每个字元处词典中的所有分词，按长度从短到长排列
+	candidates := make([][]*Token, len(text))
+	tokens := make([]*Token, seg.dict.maxTokenLength)
+	for current := range text {
+		numTokens := seg.dict.lookupTokens(
+			text[current:minInt(current+seg.dict.maxTokenLength, len(text))], tokens)
+		candidates[current] = append([]*Token{}, tokens[:numTokens]...)
+	}
+
+	// 正向最大匹配
+	for current := 0; current < len(text); {
+		token := pseudoToken(text[current])
+		if n := len(candidates[current]); n > 0 {
+			token = candidates[current][n-1]
+		}
+		report.Forward = append(report.Forward, maxMatchSegment(text, offsets, current, token))
+		current += len(token.text)
+	}
+
+	// 逆向最大匹配，从后向前每次取以当前字元结尾的最长分词
+	for end := len(text); end > 0; {
+		var token *Token
+		for start := maxInt(0, end-seg.dict.maxTokenLength); start < end && token == nil; start++ {
+			for _, t := range candidates[start] {
+				if start+len(t.text) == end {
+					token = t
+					break
+				}
+			}
+		}
+		if token == nil {
+			token = pseudoToken(text[end-1])
+		}
+		end -= len(token.text)
+		report.Backward = append(report.Backward, maxMatchSegment(text, offsets, end, token))
+	}
+	for i, j := 0, len(report.Backward)-1; i < j; i, j = i+1, j-1 {
+		report.Backward[i], report.Backward[j] = report.Backward[j], report.Backward[i]
+	}
+
+	// 规范化改变了文本时，把起止位置换回原文本中的位置
+	for _, segs := range [][]Segment{report.Forward, report.Backward} {
+		for i := range segs {
+			if normOffsets != nil {
+				segs[i].start = normOffsets.start(segs[i].start)
+				segs[i].end = normOffsets.end(segs[i].end)
+			}
+			segs[i].spaceAfter = isSpaceAt(bytes, segs[i].end)
+		}
+	}
+
+	report.Ambiguities = compareSegments(report.Forward, report.Backward)
+	return report
+}
+
+// 返回词典中没有的字元word的伪分词
+func pseudoToken(word Text) *Token {
+	return &Token{text: []Text{word}, frequency: 1, weight: 1, pos: "x"}
+}
+
+// 返回从第start个字元开始的分词token在文本中的分词
+func maxMatchSegment(text []Text, offsets []int, start int, token *Token) Segment {
+	last := start + len(token.text) - 1
+	return Segment{start: offsets[start], end: offsets[last] + len(text[last]), token: token}
+}
+
+// 找出覆盖同一段文本的两组分词中划分不同的片段
+func compareSegments(a, b []Segment) []Ambiguity {
+	var ambiguities []Ambiguity
+	for _, diff := range DiffSegmentations(a, b) {
+		ambiguity := Ambiguity{Start: diff.Start, End: diff.End}
+		for _, segment := range diff.A {
+			ambiguity.Forward = append(ambiguity.Forward, segment.token.Text())
+		}
+		for _, segment := range diff.B {
+			ambiguity.Backward = append(ambiguity.Backward, segment.token.Text())
+		}
+		ambiguities = append(ambiguities, ambiguity)
+	}
+	return ambiguities
+}
@@ -0,0 +1,40 @@
+package sego
+
+import "testing"
+
+func TestAmbiguityReport(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	report := seg.AmbiguityReport([]byte("人口中国有十三亿"))
+	expect(t, "人口/p12 中国/ 有/p3 十三亿/ ", SegmentsToString(report.Forward))
+	expect(t, "人口/p12 中/p1 国有/p9 十三亿/ ", SegmentsToString(report.Backward))
+	expect(t, "[{6 15 [中国 有] [中 国有]}]", report.Ambiguities)
+
+	// 两种匹配一致
+	report = seg.AmbiguityReport([]byte("十三亿人口！"))
+	expect(t, "十三亿/ 人口/p12 ！/x ", SegmentsToString(report.Backward))
+	expect(t, "[]", report.Ambiguities)
+
+	expect(t, "[]", seg.AmbiguityReport(nil).Ambiguities)
+
+	// 规范化改变了文本长度时，起止位置仍然是在原文本中的位置
+	seg.SetNormalization(NormNFKC)
+	text := []byte("（人口）中国有")
+	report = seg.AmbiguityReport(text)
+	expect(t, "[{12 21 [中国 有] [中 国有]}]", report.Ambiguities)
+	expect(t, "人口", string(text[report.Forward[1].Start():report.Forward[1].End()]))
+	expect(t, describeSegments(seg.Segment(text)), describeSegments(report.Forward))
+}
+
+func Test_compareSegments(t *testing.T) {
+	segs := func(bounds ...int) []Segment {
+		var output []Segment
+		for i := 1; i < len(bounds); i++ {
+			output = append(output, Segment{start: bounds[i-1], end: bounds[i], token: &Token{text: []Text{Text("x")}}})
+		}
+		return output
+	}
+	expect(t, "[]", compareSegments(segs(0, 3, 6), segs(0, 3, 6)))
+	expect(t, "[{0 6 [x x] [x]} {9 15 [x] [x x]}]", compareSegments(segs(0, 3, 6, 9, 15), segs(0, 6, 9, 12, 15)))
+}
@@ -0,0 +1,49 @@
+package sego
+
+import "sort"
+
+// TokenStat 分词在一段文本中的统计，见Analyze
+type TokenStat struct {
+	// 分词文本
+	Text string
+
+	// 分词的词性
+	Pos string
+
+	// 分词在文本中出现的次数
+	DocCount int
+
+	// 分词在词典中的词频，不在词典中的分词为0
+	DictFrequency int
+}
+
+// Analyze 对文本分词，并统计每个分词在文本中出现的次数以及它在词典中的词频
+//
+// 停用词不计入统计（StopMark模式下标记为停用词的分词同样被排除）。结果按出现次数从多到
+// 少排列，次数相同时按在文本中第一次出现的先后排列。可以用于生成关键词云等。
+func (seg *Segmenter) Analyze(bytes []byte) []TokenStat {
+	var stats []TokenStat
+	index := make(map[string]int)
+	for _, segment := range seg.Segment(bytes) {
+		if segment.stop {
+			continue
+		}
+		text := segment.token.Text()
+		if i, ok := index[text]; ok {
+			stats[i].DocCount++
+			continue
+		}
+
+		stat := TokenStat{Text: text, Pos: segment.token.pos, DocCount: 1}
+		if segment.token.inDictionary {
+			stat.DictFrequency = segment.token.frequency
+		}
+		index[text] = len(stats)
+		stats = append(stats, stat)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].DocCount > stats[j].DocCount
+	})
+	return stats
+}
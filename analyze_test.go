@@ -0,0 +1,20 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetStopWords([]string{"有"})
+	seg.SetStopMode(StopMark)
+
+	var output []string
+	for _, stat := range seg.Analyze([]byte("人口，中国有十三亿人口，人口！")) {
+		output = append(output, fmt.Sprintf("%s/%s:%d,%d", stat.Text, stat.Pos, stat.DocCount, stat.DictFrequency))
+	}
+	expect(t, "[人口/p12:3,16 ，/x:2,0 中国/:1,32 十三亿/:1,4 ！/x:1,0]", output)
+	expect(t, "0", len(seg.Analyze(nil)))
+}
@@ -0,0 +1,85 @@
+package sego
+
+import "time"
+
+// 临时提升后分词的最小路径值，保证路径值始终为正
+const minBoostedDistance = 1.0 / 1024
+
+// 一个词语的临时提升，见BoostWord
+type wordBoost struct {
+	boost   float32
+	expires time.Time
+}
+
+// 临时提升的词语，键同wordSet
+type boostSet map[string]wordBoost
+
+// BoostWord 在ttl时间内把text的路径值降低boost，之后自动恢复
+//
+// 路径值的含义见Token结构体的注释，降低boost相当于把分词的概率提高为2^boost倍，使动态
+// 规划更倾向于选择该分词，适合临时提升正在流行的词语，降低后的路径值不会小于零。只对
+// 词典中的分词有效，不修改词典，也不影响载入词典时计算好的子分词。对同一个词语再次调用
+// 时取代之前的提升，boost或ttl不大于零时撤销提升。可以在其他goroutine正在分词时调用。
+//
+// 有生效中的提升时Segment不使用EnableCache开启的缓存，以免提升过期后仍然返回旧的结果。
+func (seg *Segmenter) BoostWord(text string, boost float32, ttl time.Duration) {
+	key := seg.wordKey(text)
+	if key == "" {
+		return
+	}
+
+	seg.boostMutex.Lock()
+	defer seg.boostMutex.Unlock()
+
+	// 复制时顺便删除过期的提升
+	now := time.Now()
+	old := seg.loadBoosts()
+	set := make(boostSet, len(old)+1)
+	for word, b := range old {
+		if word != key && now.Before(b.expires) {
+			set[word] = b
+		}
+	}
+	if boost > 0 && ttl > 0 {
+		set[key] = wordBoost{boost: boost, expires: now.Add(ttl)}
+	}
+	seg.boosts.Store(set)
+	seg.cache.clear()
+}
+
+// 当前临时提升的词语，可能包括已经过期的提升
+func (seg *Segmenter) loadBoosts() boostSet {
+	set, _ := seg.boosts.Load().(boostSet)
+	return set
+}
+
+// 是否有尚未过期的提升
+func (seg *Segmenter) hasActiveBoosts() bool {
+	set := seg.loadBoosts()
+	if len(set) == 0 {
+		return false
+	}
+	now := time.Now()
+	for _, b := range set {
+		if now.Before(b.expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回分词在now时刻生效的提升，没有时为0
+func (set boostSet) boost(token *Token, now time.Time, fold bool) float32 {
+	var buf [64]byte
+	key := buf[:0]
+	for _, word := range token.text {
+		key = append(key, word...)
+	}
+	if fold {
+		key = foldCase(key)
+	}
+	if b, ok := set[string(key)]; ok && now.Before(b.expires) {
+		return b.boost
+	}
+	return 0
+}
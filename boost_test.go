@@ -0,0 +1,41 @@
+package sego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoostWord(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(8)
+	text := []byte("中国有十三亿人口")
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+
+	seg.BoostWord("国有", 4, time.Hour)
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+	expect(t, "国有", seg.SegmentDebug(text).Nodes[2].Best.Text())
+
+	// 副本同样有效
+	expect(t, "中/p1 国有/p9 ", SegmentsToString(seg.Clone().Segment([]byte("中国有"))))
+
+	// 撤销
+	seg.BoostWord("国有", 0, time.Hour)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+
+	// 过期后自动恢复
+	seg.BoostWord("国有", 4, 20*time.Millisecond)
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+	time.Sleep(30 * time.Millisecond)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+
+	// 过期的提升不再让Segment跳过缓存
+	expect(t, "1", seg.cache.len())
+	seg.BoostWord("人口", 1, time.Hour)
+	expect(t, "1", len(seg.loadBoosts()))
+
+	// 整数路径值同样生效，路径值不会小于零
+	seg.SetIntegerDistance(true)
+	seg.BoostWord("国有", 100, time.Hour)
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+}
@@ -0,0 +1,44 @@
+package sego
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SetSplitChars 设置分隔字符，chars中的每个字符都是一个分隔字符，为空时不分隔，默认为空
+//
+// 分词不会跨过分隔字符，即使词典中有跨过它的分词，比如设置为"/"时，词典中的"tcp/ip"
+// 在"tcp/ip"中也分为"tcp"、"/"和"ip"三个分词，分隔字符本身单独成为分词。与
+// SetGlueChars的作用相反。分隔字符应为标点等本身就单独成为字元的字符，字母和数字
+// 在英文单词和数字中间时不起作用。需要在分词前设置。
+func (seg *Segmenter) SetSplitChars(chars string) {
+	seg.splitChars = chars
+}
+
+// 字元是否为一个分隔字符
+func (seg *Segmenter) isSplitWord(word Text) bool {
+	r, size := utf8.DecodeRune(word)
+	return size == len(word) && strings.ContainsRune(seg.splitChars, r)
+}
+
+// 在分隔字符处把字元数组分为几段，分别分词后把分词追加到dst之后，参数同segmentWords
+func (seg *Segmenter) segmentWordsAtSplits(dst []Segment, text []Text, offsets []int, searchMode bool,
+	forbidden wordSet, sc *scratch) []Segment {
+	start := 0
+	for i, word := range text {
+		if !seg.isSplitWord(word) {
+			continue
+		}
+		for _, piece := range [][2]int{{start, i}, {i, i + 1}} {
+			if piece[0] < piece[1] {
+				dst = seg.segmentWords(dst, text[piece[0]:piece[1]], offsets[piece[0]:piece[1]],
+					searchMode, forbidden, sc)
+			}
+		}
+		start = i + 1
+	}
+	if start < len(text) {
+		dst = seg.segmentWords(dst, text[start:], offsets[start:], searchMode, forbidden, sc)
+	}
+	return dst
+}
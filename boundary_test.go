@@ -0,0 +1,24 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetSplitChars(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddWord("TCP/IP", 10, "nz")
+	seg.AddWord("国/人", 100, "")
+
+	text := []byte("TCP/IP中国/人口")
+	expect(t, "tcp /ip/nz 中/p1 国/人/ 口/p7 ", SegmentsToString(seg.Segment(text)))
+
+	seg.SetSplitChars("/|")
+	segments := seg.Segment(text)
+	expect(t, "tcp/x //x ip/x 中国/ //x 人口/p12 ", SegmentsToString(segments))
+	expect(t, "3 4", fmt.Sprint(segments[1].Start(), segments[1].End()))
+
+	// 分隔字符在文本首尾或者连续出现
+	expect(t, "//x |/x 中国/ //x ", SegmentsToString(seg.Segment([]byte("/|中国/"))))
+}
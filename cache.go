@@ -0,0 +1,132 @@
+package sego
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// 分词结果的LRU缓存，见EnableCache
+type segmentCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+
+	// 按文本的哈希值索引，链表中最近使用的在前
+	entries map[uint64]*list.Element
+	order   *list.List
+
+	// 每次清空缓存时加一，清空前开始的分词结果不再放入缓存
+	generation uint64
+}
+
+type cacheEntry struct {
+	hash     uint64
+	text     string
+	segments []Segment
+}
+
+func newSegmentCache(maxEntries int) *segmentCache {
+	return &segmentCache{
+		maxEntries: maxEntries,
+		entries:    make(map[uint64]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// EnableCache 为Segment开启最多保存maxEntries段文本分词结果的LRU缓存，maxEntries
+// 不大于零时关闭缓存
+//
+// 缓存按文本的哈希值查找，适合反复对同样的热门查询分词。命中时返回缓存结果的副本，
+// 调用者可以随意修改返回的slice；缓存保存的是输入文本的副本，分词后可以复用输入的
+// 内存。缓存可以在多个goroutine同时分词时使用，但开启、关闭缓存不能与分词同时进行。
+// 重建词典（包括AddWord等）以及调用SetStopWords、ForbidWord、AllowWord时缓存会被
+// 清空；修改其他设置后需要再次调用EnableCache清空缓存。每次调用EnableCache都重新
+// 开始一个空的缓存，Overlay返回的分词器不使用缓存。
+func (seg *Segmenter) EnableCache(maxEntries int) {
+	if maxEntries <= 0 {
+		seg.cache = nil
+		return
+	}
+	seg.cache = newSegmentCache(maxEntries)
+}
+
+// 使用缓存对文本分词，结果同internalSegment(bytes, false)
+func (seg *Segmenter) cachedSegment(bytes []byte) []Segment {
+	hash := hashText(bytes)
+	segments, generation, ok := seg.cache.get(hash, bytes)
+	if ok {
+		seg.stats.add(bytes)
+		return segments
+	}
+	// 伪分词和模式匹配的分词的文本指向输入，复制一份再分词，调用者之后修改输入不影响缓存
+	owned := append([]byte{}, bytes...)
+	segments = seg.internalSegment(owned, false)
+	seg.cache.put(hash, owned, segments, generation)
+	return segments
+}
+
+func hashText(text []byte) uint64 {
+	hash := fnv.New64a()
+	hash.Write(text)
+	return hash.Sum64()
+}
+
+// 查找缓存的分词结果并返回副本，同时返回当前的清空次数
+func (cache *segmentCache) get(hash uint64, text []byte) ([]Segment, uint64, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[hash]
+	if !ok {
+		return nil, cache.generation, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if entry.text != string(text) {
+		// 哈希值冲突
+		return nil, cache.generation, false
+	}
+	cache.order.MoveToFront(element)
+	return append([]Segment{}, entry.segments...), cache.generation, true
+}
+
+// 保存分词结果的副本，generation与当前的清空次数不同时说明结果可能已经过期，不保存
+func (cache *segmentCache) put(hash uint64, text []byte, segments []Segment, generation uint64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if generation != cache.generation {
+		return
+	}
+	entry := &cacheEntry{hash: hash, text: string(text), segments: append([]Segment{}, segments...)}
+	if element, ok := cache.entries[hash]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[hash] = cache.order.PushFront(entry)
+	if cache.order.Len() > cache.maxEntries {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).hash)
+	}
+}
+
+// 清空缓存，cache为nil时什么也不做
+func (cache *segmentCache) clear() {
+	if cache == nil {
+		return
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = make(map[uint64]*list.Element)
+	cache.order.Init()
+	cache.generation++
+}
+
+// 缓存的分词结果数
+func (cache *segmentCache) len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.order.Len()
+}
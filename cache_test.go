@@ -0,0 +1,109 @@
+package sego
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestEnableCache(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(2)
+	text := []byte("中国有十三亿人口")
+
+	segments := seg.Segment(text)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(segments))
+	expect(t, "1", seg.cache.len())
+
+	// 命中时返回副本，修改结果不影响缓存
+	segments[0] = segments[1]
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+	expect(t, "2", seg.Stats().Calls)
+
+	// 超过容量时淘汰最久未使用的结果
+	seg.Segment([]byte("人口"))
+	seg.Segment(text)
+	seg.Segment([]byte("中国"))
+	expect(t, "2", seg.cache.len())
+	_, _, ok := seg.cache.get(hashText([]byte("人口")), []byte("人口"))
+	expect(t, "false", ok)
+	_, _, ok = seg.cache.get(hashText(text), text)
+	expect(t, "true", ok)
+
+	// 修改词典和停用词时清空缓存
+	seg.AddWord("有十", 1000, "q")
+	expect(t, "0", seg.cache.len())
+	expect(t, "中国/ 有十/q 三/ 亿/p5 人口/p12 ", SegmentsToString(seg.Segment(text)))
+	seg.SetStopWords([]string{"人口"})
+	expect(t, "中国/ 有十/q 三/ 亿/p5 ", SegmentsToString(seg.Segment(text)))
+	seg.ForbidWord("有十")
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(seg.Segment(text)))
+
+	seg.EnableCache(0)
+	expect(t, "true", seg.cache == nil)
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(seg.Segment(text)))
+}
+
+func TestCacheOwnsInput(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(10)
+
+	// "十"和"！"不在词典中，伪分词的文本不能指向调用者之后会修改的输入
+	text := []byte("十中国！")
+	expect(t, "十/x 中国/ ！/x ", SegmentsToString(seg.Segment(text)))
+	copy(text, "丁")
+	expect(t, "十/x 中国/ ！/x ", SegmentsToString(seg.Segment([]byte("十中国！"))))
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(4)
+	texts := []string{"中国有十三亿人口", "人口", "中国", "十三亿", "国有", "三亿人"}
+	expected := make([]string, len(texts))
+	for i, text := range texts {
+		expected[i] = SegmentsToString(seg.Segment([]byte(text)))
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				j := (g + i) % len(texts)
+				if actual := SegmentsToString(seg.Segment([]byte(texts[j]))); actual != expected[j] {
+					t.Errorf("%s: 期待值=%q, 实际=%q", texts[j], expected[j], actual)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// 与BenchmarkSegmentUncached对比缓存命中时的加速
+func BenchmarkSegmentCached(b *testing.B) {
+	benchmarkCache(b, 100)
+}
+
+func BenchmarkSegmentUncached(b *testing.B) {
+	benchmarkCache(b, 0)
+}
+
+func benchmarkCache(b *testing.B, maxEntries int) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(maxEntries)
+	var queries [][]byte
+	for i := 0; i < 10; i++ {
+		queries = append(queries, []byte(fmt.Sprintf("中国有十三亿人口%d，三亿人口国有", i)))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		seg.Segment(queries[i%len(queries)])
+	}
+}
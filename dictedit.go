@@ -0,0 +1,165 @@
+package sego
+
+import (
+	"bytes"
+	"math"
+)
+
+// AddWord 向词典中添加一个分词并立即重建词典，见Rebuild
+//
+// weight为分词的权重（词频），pos为词性，多个词性用";"分隔。词典中已有同样文本的分词时
+// 新分词将取代原有的分词，原有分词与同一行其他分词的同义词关系也随之解除。
+// 每次调用都要重建整个词典，批量添加时请使用AddWordDeferred，最后调用一次Rebuild。
+func (seg *Segmenter) AddWord(text string, weight float64, pos string) {
+	seg.AddWordDeferred(text, weight, pos)
+	seg.Rebuild()
+}
+
+// AddWordDeferred 同AddWord，但不重建词典，调用Rebuild后才生效
+func (seg *Segmenter) AddWordDeferred(text string, weight float64, pos string) {
+	words := seg.splitText([]byte(text))
+	if len(words) == 0 || weight <= 0 {
+		return
+	}
+	seg.removeWord(string(textSliceToBytes(words)))
+
+	token := &Token{text: words, frequency: int(weight), weight: weight, inDictionary: true}
+	token.pos, token.posList = parsePos(pos)
+	seg.dict.groups = append(seg.dict.groups, []*Token{token})
+}
+
+// RemoveWord 从词典中删除一个分词并立即重建词典，见Rebuild
+//
+// 只能删除词典文件中或者用AddWord添加的分词，由子分词的同义词组合出的同义词会在
+// 重建时重新生成。
+func (seg *Segmenter) RemoveWord(text string) {
+	seg.RemoveWordDeferred(text)
+	seg.Rebuild()
+}
+
+// RemoveWordDeferred 同RemoveWord，但不重建词典，调用Rebuild后才生效
+func (seg *Segmenter) RemoveWordDeferred(text string) {
+	seg.removeWord(string(textSliceToBytes(seg.splitText([]byte(text)))))
+}
+
+// Bump 把分词的权重（词频）增加delta并立即重建词典，delta为负数时降低权重
+//
+// 用于根据使用反馈调整词典，比如用户纠正了分词结果时提高正确分词的词频，使动态规划在
+// 以后更倾向于选择它。重建词典时重新计算所有分词的路径值以及词典的总权重。权重最低降为
+// 1，分词不会因此被删除；词典中没有该分词且delta为正数时以delta为权重添加，没有词性。
+// 在副本（见Clone）上调整只在共享词典中的分词时，从它原来的权重开始调整，词性、来源
+// 以及同一行的同义词都保留。调整后的词典可以用Dictionary().WriteText保存。与AddWord
+// 一样每次调用都要重建整个词典，批量调整时请使用BumpDeferred，最后调用一次Rebuild。
+//
+// 重建不能与分词同时进行，在线调整时需要由调用者保证调用Bump期间没有其他goroutine在
+// 使用该分词器，或者在分词器的副本上调整后再整体替换。
+func (seg *Segmenter) Bump(word string, delta int) {
+	seg.BumpDeferred(word, delta)
+	seg.Rebuild()
+}
+
+// BumpDeferred 同Bump，但不重建词典，调用Rebuild后才生效
+func (seg *Segmenter) BumpDeferred(word string, delta int) {
+	words := seg.splitText([]byte(word))
+	if len(words) == 0 || delta == 0 {
+		return
+	}
+	if seg.dict == nil {
+		seg.dict = NewDictionary()
+	}
+
+	matches := seg.wordMatcher(string(textSliceToBytes(words)))
+	found := false
+	for _, group := range seg.dict.groups {
+		for _, token := range group {
+			if matches(token) {
+				token.weight = math.Max(token.weight+float64(delta), 1)
+				token.frequency = int(token.weight)
+				found = true
+			}
+		}
+	}
+	if found {
+		return
+	}
+
+	// 只在底层词典中的分词从原来的权重开始调整，保留词性和同一行的同义词
+	if group, token := seg.copyParentGroup(words); token != nil {
+		token.weight = math.Max(token.weight+float64(delta), 1)
+		token.frequency = int(token.weight)
+		seg.dict.groups = append(seg.dict.groups, group)
+	} else if delta > 0 {
+		token := &Token{text: words, frequency: delta, weight: float64(delta), inDictionary: true}
+		seg.dict.groups = append(seg.dict.groups, []*Token{token})
+	}
+}
+
+// 在底层词典的原始分词中找出文本为words、并且实际生效的分词，返回它所在的组中生效的
+// 分词的副本，以及其中文本为words的分词，没有时返回nil。叠加词典修改底层词典中的分词
+// 时使用副本，以免重建叠加词典时改动共享的分词
+func (seg *Segmenter) copyParentGroup(words []Text) ([]*Token, *Token) {
+	matches := seg.wordMatcher(string(textSliceToBytes(words)))
+	for dict := seg.dict.parent; dict != nil; dict = dict.parent {
+		tokens := make([]*Token, dict.maxTokenLength)
+		effective := func(token *Token) bool {
+			if len(token.text) > len(tokens) {
+				return false
+			}
+			numTokens := dict.lookupTokens(token.text, tokens)
+			return numTokens > 0 && tokens[numTokens-1] == token
+		}
+		for _, group := range dict.groups {
+			for _, token := range group {
+				if !matches(token) || !effective(token) {
+					continue
+				}
+				var copies []*Token
+				var found *Token
+				for _, t := range group {
+					if effective(t) {
+						c := *t
+						copies = append(copies, &c)
+						if t == token {
+							found = &c
+						}
+					}
+				}
+				return copies, found
+			}
+		}
+	}
+	return nil, nil
+}
+
+// 返回判断分词文本是否为key的函数，保留大小写时忽略大小写比较，见SetPreserveCase
+func (seg *Segmenter) wordMatcher(key string) func(token *Token) bool {
+	if seg.split.preserveCase {
+		folded := foldCase([]byte(key))
+		return func(token *Token) bool {
+			return bytes.Equal(foldCase(textSliceToBytes(token.text)), folded)
+		}
+	}
+	return func(token *Token) bool { return token.TextEquals(key) }
+}
+
+// 从原始分词中删除文本为key的分词，删除后为空的组一并删除
+func (seg *Segmenter) removeWord(key string) {
+	if seg.dict == nil {
+		seg.dict = NewDictionary()
+	}
+	matches := seg.wordMatcher(key)
+
+	groups := seg.dict.groups[:0]
+	for _, group := range seg.dict.groups {
+		kept := group[:0]
+		for _, token := range group {
+			if !matches(token) {
+				kept = append(kept, token)
+			}
+		}
+		if len(kept) > 0 {
+			groups = append(groups, kept)
+		}
+	}
+	seg.dict.groups = groups
+}
@@ -0,0 +1,168 @@
+package sego
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddWord(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	numTokens := seg.Dictionary().NumTokens()
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 取代已有的分词
+	seg.AddWord("中国", 32, "ns")
+	expect(t, fmt.Sprint(numTokens), seg.Dictionary().NumTokens())
+	expect(t, "中国/ns 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 批量添加后重建
+	seg.AddWordDeferred("亿人", 1000, "q")
+	seg.AddWordDeferred("有十", 1000, "q")
+	expect(t, "中国/ns 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+	seg.Rebuild()
+	expect(t, fmt.Sprint(numTokens+2), seg.Dictionary().NumTokens())
+	expect(t, "中国/ns 有十/q 三/ 亿人/q 口/p7 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 重建不改变结果
+	seg.Rebuild()
+	expect(t, fmt.Sprint(numTokens+2), seg.Dictionary().NumTokens())
+	expect(t, "中国/ns 有十/q 三/ 亿人/q 口/p7 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+}
+
+func TestRemoveWord(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	expect(t, "hi/p2 hoho/p2 hello/p2 world/p3 hi world/p1 hoho world/p1 hello world/p1 ",
+		SegmentsToString(seg.FullSegment([]byte("hello world"))))
+
+	seg.RemoveWord("hello world")
+	expect(t, "hello/p2 world/p3 ", SegmentsToString(seg.Segment([]byte("hello world"))))
+
+	// 删除一个同义词
+	seg.RemoveWordDeferred("hi")
+	seg.Rebuild()
+	expect(t, "hoho/p2 hello/p2 world/p3 ", SegmentsToString(seg.FullSegment([]byte("hello world"))))
+	expect(t, "hi/x ", SegmentsToString(seg.Segment([]byte("hi"))))
+}
+
+func TestBump(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	total := seg.Dictionary().TotalFrequency()
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 用户纠正为"国有"后提高其词频
+	seg.Bump("国有", 1000)
+	expect(t, fmt.Sprint(total+1000), seg.Dictionary().TotalFrequency())
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 权重最低降为1
+	seg.Bump("国有", -10000)
+	var buf bytes.Buffer
+	seg.Dictionary().WriteText(&buf)
+	expect(t, "true", strings.Contains(buf.String(), "国有 1.0 p9\n"))
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 词典中没有的分词
+	seg.BumpDeferred("亿人", 1000)
+	seg.BumpDeferred("有十", -1000)
+	seg.Rebuild()
+	expect(t, "有/p3 十/x ", SegmentsToString(seg.Segment([]byte("有十"))))
+	expect(t, "中国/ 有/p3 十三/p10 亿人/ 口/p7 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 调整后的词典可以写出
+	buf.Reset()
+	expect(t, "<nil>", seg.Dictionary().WriteText(&buf))
+	expect(t, "true", strings.Contains(buf.String(), "亿人 1000\n"))
+}
+
+func TestBumpOnClone(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 只在共享词典中的分词从原来的权重开始调整，保留词性和来源
+	clone := seg.Clone()
+	clone.Bump("国有", 5)
+	token := clone.lookupWord("国有")
+	expect(t, "13 p9 testdata/test_dict2.txt", fmt.Sprint(token.Frequency(), " ", token.Pos(), " ", token.Source()))
+	clone.Bump("中", -5)
+	expect(t, "59 p1", fmt.Sprint(clone.lookupWord("中").Frequency(), " ", clone.lookupWord("中").Pos()))
+
+	// 共享词典不受影响
+	expect(t, "8", seg.lookupWord("国有").Frequency())
+	expect(t, "64", seg.lookupWord("中").Frequency())
+}
+
+func TestAddWordWithoutDictionary(t *testing.T) {
+	var seg Segmenter
+	seg.AddWord("中国", 10, "ns")
+	seg.AddWord("人口", 10, "n")
+	expect(t, "中国/ns 人口/n ", SegmentsToString(seg.Segment([]byte("中国人口"))))
+}
+
+func TestWriteText(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt," +
+		"testdata/test_dict4.txt,testdata/test_dict6.txt,testdata/test_dict7.txt,testdata/test_dict8.txt")
+	seg.AddWord("新词", 1, "n;v")
+
+	var first bytes.Buffer
+	expect(t, "<nil>", seg.Dictionary().WriteText(&first))
+	for _, line := range []string{
+		"hello 2 p2|hi 2 p2|hoho 2 p2\n",
+		"\"__VERTICAL_BAR__\" 2 __STOP__\n",
+		"人口 0.0031 n\n",
+		"web 2.0 100 n\n",
+		"研究 20 v;n\n",
+		"新词 1.0 n;v\n",
+	} {
+		expect(t, "true", strings.Contains(first.String(), line))
+	}
+
+	dir, err := ioutil.TempDir("", "sego")
+	expect(t, "<nil>", err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "dict.txt")
+	expect(t, "<nil>", ioutil.WriteFile(file, first.Bytes(), 0644))
+
+	// 重新载入后得到相同的词典
+	var reloaded Segmenter
+	parseErrors, err := reloaded.LoadDictionaryWithErrors(file)
+	expect(t, "<nil>", err)
+	expect(t, "0", len(parseErrors))
+	var second bytes.Buffer
+	expect(t, "<nil>", reloaded.Dictionary().WriteText(&second))
+	expect(t, first.String(), second.String())
+
+	expect(t, fmt.Sprint(seg.Dictionary().NumTokens()), reloaded.Dictionary().NumTokens())
+	expect(t, fmt.Sprint(seg.Dictionary().TotalWeight()), reloaded.Dictionary().TotalWeight())
+	for _, text := range []string{"中国有十三亿人口", "hello world", "web 2.0", "研究生命起源新词"} {
+		expect(t, SegmentsToString(seg.FullSegment([]byte(text))),
+			SegmentsToString(reloaded.FullSegment([]byte(text))))
+	}
+}
+
+func TestPosTags(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddWord("人口", 16, "n;nn")
+	seg.AddWord("亿人", 16, "n")
+
+	tags := seg.Dictionary().PosTags()
+	expect(t, "3", tags[""])
+	expect(t, "2", tags["n"])
+	expect(t, "1", tags["nn"])
+	expect(t, "0", tags["p12"])
+	expect(t, "1", tags["p1"])
+	expect(t, "11", len(tags))
+
+	// 叠加词典只统计自己的分词
+	overlay, _ := seg.Overlay(strings.NewReader("中国 32 ns\n"))
+	expect(t, "map[ns:1]", fmt.Sprint(overlay.Dictionary().PosTags()))
+}
@@ -1,6 +1,17 @@
 package sego
 
-import "github.com/adamzy/cedar-go"
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adamzy/cedar-go"
+)
 
 // Dictionary 结构体实现了一个字串前缀树，一个分词可能出现在叶子节点也有可能出现在非叶节点
 type Dictionary struct {
@@ -8,6 +19,35 @@ type Dictionary struct {
 	maxTokenLength int          // 词典中最长的分词
 	tokens         []*Token     // 词典中所有的分词，方便遍历
 	totalFrequency int64        // 词典中所有分词的频率之和
+	totalWeight    float64      // 词典中所有分词的权重之和
+
+	// 从词典文件读入或者用AddWord添加的原始分词，每组为同一行中互为同义词的分词，
+	// 排在前面的组优先，见Segmenter.Rebuild
+	groups [][]*Token
+
+	// 叠加词典的底层词典，查找分词时本词典中的分词优先，见Segmenter.Overlay
+	parent *Dictionary
+
+	// 查找分词时忽略大小写，分词本身保留原有的大小写，见Segmenter.SetPreserveCase
+	foldCase bool
+
+	// 词典中是否有词性为"__STOP__"的分词，没有时分词结果不需要处理停用词
+	stopTokens bool
+}
+
+// DictParseError 词典文件中一行格式有误的记录，见Segmenter.LoadDictionaryWithErrors
+type DictParseError struct {
+	File   string // 词典文件名
+	Line   int    // 行号，从1开始
+	Reason string // 错误原因
+}
+
+// Error 实现error接口，格式为"文件名:行号: 原因"，没有文件名时为"行号: 原因"
+func (e DictParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d: %s", e.Line, e.Reason)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
 }
 
 // NewDictionary 创建词典
@@ -25,14 +65,39 @@ func (dict *Dictionary) NumTokens() int {
 	return len(dict.tokens)
 }
 
+// PosTags 统计词典中用到的每个词性以及使用该词性的分词数
+//
+// 有多个词性的分词每个词性各计一次，没有词性的分词计在空字符串下。与NumTokens相同，
+// 只统计本词典中的分词，不包括叠加词典的底层词典。可以用来检查词典中是否有拼错的词性。
+func (dict *Dictionary) PosTags() map[string]int {
+	tags := make(map[string]int)
+	for _, token := range dict.tokens {
+		for _, pos := range token.PosList() {
+			tags[pos]++
+		}
+		if token.pos == "" {
+			tags[""]++
+		}
+	}
+	return tags
+}
+
 // TotalFrequency 词典中所有分词的频率之和
 func (dict *Dictionary) TotalFrequency() int64 {
 	return dict.totalFrequency
 }
 
+// TotalWeight 词典中所有分词的权重之和，整数词频的分词权重即为词频
+func (dict *Dictionary) TotalWeight() float64 {
+	return dict.totalWeight
+}
+
 // 向词典中加入一个分词
 func (dict *Dictionary) addToken(token *Token) {
 	bytes := textSliceToBytes(token.text)
+	if dict.foldCase {
+		bytes = foldCase(bytes)
+	}
 	_, err := dict.trie.Get(bytes)
 	if err == nil {
 		return
@@ -41,26 +106,144 @@ func (dict *Dictionary) addToken(token *Token) {
 	dict.trie.Insert(bytes, dict.NumTokens())
 	dict.tokens = append(dict.tokens, token)
 	dict.totalFrequency += int64(token.frequency)
+	dict.totalWeight += token.weight
+	if token.IsStop() {
+		dict.stopTokens = true
+	}
 	if len(token.text) > dict.maxTokenLength {
 		dict.maxTokenLength = len(token.text)
 	}
 }
 
-// 在词典中查找和字元组words可以前缀匹配的所有分词
+// 本词典或者底层词典中是否有词性为"__STOP__"的分词
+func (dict *Dictionary) hasStopTokens() bool {
+	for ; dict != nil; dict = dict.parent {
+		if dict.stopTokens {
+			return true
+		}
+	}
+	return false
+}
+
+// 在词典中查找和字元组words可以前缀匹配的所有分词，分词按长度从短到长排列
 // 返回值为找到的分词数
 func (dict *Dictionary) lookupTokens(words []Text, tokens []*Token) (numOfTokens int) {
+	if dict.parent != nil {
+		numOfTokens = dict.parent.lookupTokens(words, tokens)
+	}
+
 	var id, value int
 	var err error
-	for _, word := range words {
+	for length, word := range words {
+		if dict.foldCase {
+			word = foldCase(word)
+		}
 		id, err = dict.trie.Jump(word, id)
 		if err != nil {
 			break
 		}
 		value, err = dict.trie.Value(id)
-		if err == nil {
-			tokens[numOfTokens] = dict.tokens[value]
+		if err != nil {
+			continue
+		}
+		token := dict.tokens[value]
+		if dict.parent == nil {
+			tokens[numOfTokens] = token
 			numOfTokens++
+			continue
 		}
+
+		// 按长度插入到底层词典的分词中，长度相同即文本相同时取代底层词典的分词
+		i := 0
+		for i < numOfTokens && len(tokens[i].text) <= length {
+			i++
+		}
+		if i < numOfTokens && len(tokens[i].text) == length+1 {
+			tokens[i] = token
+			continue
+		}
+		copy(tokens[i+1:numOfTokens+1], tokens[i:numOfTokens])
+		tokens[i] = token
+		numOfTokens++
 	}
 	return
 }
+
+// 返回忽略大小写时使用的文本，即转为小写的文本；没有需要转换的字母时直接返回text
+func foldCase(text []byte) []byte {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if unicode.ToLower(r) != r {
+			return bytes.ToLower(text)
+		}
+		i += size
+	}
+	return text
+}
+
+// WriteText 把词典写为词典文件的文本格式，格式见Segmenter.LoadDictionary
+//
+// 只写出从词典文件读入或者用AddWord添加的分词，每行一组，同一行中互为同义词的分词
+// 用"|"分隔。子分词以及由子分词的同义词组合出的同义词会在载入时重新生成，因此写出的
+// 文件重新载入后得到相同的词典。
+func (dict *Dictionary) WriteText(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	for _, group := range dict.groups {
+		for i, token := range group {
+			if i > 0 {
+				writer.WriteString("|")
+			}
+			writer.WriteString(formatDictEntry(token))
+		}
+		if _, err := writer.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// 把分词格式化为词典文件中的一项
+func formatDictEntry(token *Token) string {
+	text := joinDictText(token.text)
+	if strings.Contains(text, "|") {
+		// LoadDictionary只在带引号或者带词性时还原转义的"|"
+		text = "\"" + strings.Replace(text, "|", "__VERTICAL_BAR__", -1) + "\""
+	}
+
+	// 整数词频低于下限时会被过滤，写成浮点权重
+	freq := strconv.Itoa(token.frequency)
+	if token.weight != float64(token.frequency) || token.frequency < minTokenFrequency {
+		freq = strconv.FormatFloat(token.weight, 'f', -1, 64)
+		if !strings.Contains(freq, ".") {
+			freq += ".0"
+		}
+	}
+
+	entry := text + " " + freq
+	if pos := strings.Join(token.PosList(), ";"); pos != "" {
+		entry += " " + pos
+	}
+	if token.group != "" {
+		entry += " @" + token.group
+	}
+	return entry
+}
+
+// 把字元拼接为词典文件中的文本，只在相邻的两个字母数字字元之间加空格，
+// 这样"web 2.0"不会被写成"web 2 .0"
+func joinDictText(text []Text) string {
+	var output strings.Builder
+	for i, word := range text {
+		if i > 0 && isAlnumWord(text[i-1]) && isAlnumWord(word) {
+			output.WriteByte(' ')
+		}
+		output.Write(word)
+	}
+	return output.String()
+}
+
+// 判断字元是否为英文单词或者数字
+func isAlnumWord(word Text) bool {
+	r, size := utf8.DecodeRune(word)
+	return size <= 2 && (unicode.IsLetter(r) || unicode.IsNumber(r))
+}
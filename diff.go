@@ -0,0 +1,60 @@
+package sego
+
+// SegDiff 两组分词划分不同的一段文本，见DiffSegmentations
+type SegDiff struct {
+	// 片段在文本中的起始字节位置
+	Start int
+
+	// 片段在文本中的结束字节位置（不包括该位置）
+	End int
+
+	// 第一组分词中落在片段内的分词
+	A []Segment
+
+	// 第二组分词中落在片段内的分词
+	B []Segment
+}
+
+// DiffSegmentations 找出同一段文本的两组分词结果中划分不同的片段，按在文本中的位置排列
+//
+// a和b需要按从前向后的顺序排列，比如修改词典前后分别调用Segment的结果。起止位置相同的
+// 分词视为一致，其余分词按位置重叠的关系连成片段，每个片段给出两边各自的分词；只在一边
+// 出现的分词（比如被当作停用词过滤掉的）单独成为一个片段，另一边为空。分词的词性不参与
+// 比较。返回的A、B与a、b共用内存。
+func DiffSegmentations(a, b []Segment) []SegDiff {
+	var diffs []SegDiff
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i].start == b[j].start && a[i].end == b[j].end {
+			i++
+			j++
+			continue
+		}
+
+		// 从靠前的分词开始，把与片段重叠的分词都并入片段
+		fromA, fromB := i, j
+		var diff SegDiff
+		if j == len(b) || i < len(a) && a[i].start <= b[j].start {
+			diff.Start, diff.End = a[i].start, a[i].end
+			i++
+		} else {
+			diff.Start, diff.End = b[j].start, b[j].end
+			j++
+		}
+		for {
+			if i < len(a) && a[i].start < diff.End {
+				diff.End = maxInt(diff.End, a[i].end)
+				i++
+			} else if j < len(b) && b[j].start < diff.End {
+				diff.End = maxInt(diff.End, b[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+		diff.A = a[fromA:i:i]
+		diff.B = b[fromB:j:j]
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
@@ -0,0 +1,32 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffSegmentations(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口，国有企业")
+	before := seg.Segment(text)
+
+	clone := seg.Clone()
+	clone.AddWord("国有企业", 100, "n")
+	clone.AddWord("亿人", 100, "n")
+	clone.SetStopWords([]string{"，"})
+	after := clone.Segment(text)
+
+	var output []string
+	for _, diff := range DiffSegmentations(before, after) {
+		output = append(output, fmt.Sprintf("%d-%d %s| %s", diff.Start, diff.End,
+			SegmentsToString(diff.A), SegmentsToString(diff.B)))
+	}
+	expect(t, "[9-24 十三亿/ 人口/p12 | 十三/p10 亿人/n 口/p7  "+
+		"24-27 ，/x |  "+
+		"27-39 国有/p9 企/x 业/x | 国有企业/n ]", output)
+
+	// 相同的分词没有差异
+	expect(t, "0", len(DiffSegmentations(before, before)))
+	expect(t, fmt.Sprint(len(before)), len(DiffSegmentations(nil, before)))
+}
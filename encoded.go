@@ -0,0 +1,17 @@
+package sego
+
+import "golang.org/x/text/encoding"
+
+// SegmentEncoded 对用enc编码的文本分词，比如GBK编码的文本可以使用
+// golang.org/x/text/encoding/simplifiedchinese.GBK
+//
+// 文本先被转换为UTF-8再分词，返回的分词起止位置是转换后UTF-8文本中的字节位置，
+// 而不是原编码中的位置。文本无法按enc解码时返回error，转换后的文本超过
+// SetMaxInputBytes设置的长度时返回ErrInputTooLong。
+func (seg *Segmenter) SegmentEncoded(bytes []byte, enc encoding.Encoding) ([]Segment, error) {
+	text, err := enc.NewDecoder().Bytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return seg.SegmentWithError(text)
+}
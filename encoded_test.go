@@ -0,0 +1,30 @@
+package sego
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestSegmentEncoded(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	for _, enc := range []encoding.Encoding{
+		simplifiedchinese.GBK,
+		unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+		unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+	} {
+		bytes, err := enc.NewEncoder().Bytes([]byte("中国有十三亿人口"))
+		expect(t, "<nil>", err)
+
+		segments, err := seg.SegmentEncoded(bytes, enc)
+		expect(t, "<nil>", err)
+		expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(segments))
+
+		// 起止位置为UTF-8文本中的位置
+		expect(t, "18", segments[3].Start())
+	}
+}
@@ -0,0 +1,63 @@
+package sego
+
+// Evaluate 按分词的起止位置评估分词结果pred相对于标准答案gold的准确率、召回率和F1值
+//
+// gold和pred中的每一项为同一句文本的标准分词和待评估的分词，起止位置相同的分词视为
+// 切分正确，与分词的文本和词性无关，这也是中文分词评测的通常做法。两者长度不同时，缺少的
+// 句子按没有分词计算。没有待评估的分词时准确率为0，没有标准分词时召回率为0。标准分词
+// 通常来自人工切分好的语料，可以用SegmentsFromWords得到。
+func Evaluate(gold, pred [][]Segment) (precision, recall, f1 float64) {
+	type span struct{ start, end int }
+	var numGold, numPred, numCorrect int
+	for i := 0; i < len(gold) || i < len(pred); i++ {
+		spans := make(map[span]bool)
+		if i < len(gold) {
+			for _, segment := range gold[i] {
+				spans[span{segment.start, segment.end}] = true
+			}
+			numGold += len(gold[i])
+		}
+		if i < len(pred) {
+			for _, segment := range pred[i] {
+				if spans[span{segment.start, segment.end}] {
+					numCorrect++
+					// 重复的分词只计一次
+					delete(spans, span{segment.start, segment.end})
+				}
+			}
+			numPred += len(pred[i])
+		}
+	}
+
+	if numPred > 0 {
+		precision = float64(numCorrect) / float64(numPred)
+	}
+	if numGold > 0 {
+		recall = float64(numCorrect) / float64(numGold)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return
+}
+
+// SegmentsFromWords 把切分好的词语依次排列为分词，用作Evaluate的标准答案
+//
+// 分词的起止位置按词语首尾相接计算，对应的文本为strings.Join(words, "")。分词不在
+// 词典中，也没有词性。
+func SegmentsFromWords(words []string) []Segment {
+	segments := make([]Segment, 0, len(words))
+	position := 0
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			start: position,
+			end:   position + len(word),
+			token: &Token{text: splitTextToWords([]byte(word))},
+		})
+		position += len(word)
+	}
+	return segments
+}
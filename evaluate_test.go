@@ -0,0 +1,40 @@
+package sego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 预测"中国/有/十三亿/人口"，其中"中国"、"有"、"人口"正确
+	gold := [][]Segment{
+		SegmentsFromWords(strings.Fields("中国 有 十三 亿 人口")),
+		SegmentsFromWords(strings.Fields("人口")),
+	}
+	pred := [][]Segment{
+		seg.Segment([]byte("中国有十三亿人口")),
+		seg.Segment([]byte("人口")),
+	}
+	precision, recall, f1 := Evaluate(gold, pred)
+	expect(t, "0.800 0.667 0.727", fmt.Sprintf("%.3f %.3f %.3f", precision, recall, f1))
+
+	precision, recall, f1 = Evaluate(gold, gold)
+	expect(t, "1 1 1", fmt.Sprint(precision, recall, f1))
+
+	// 缺少的句子按没有分词计算
+	precision, recall, f1 = Evaluate(gold, pred[:1])
+	expect(t, "0.750 0.500 0.600", fmt.Sprintf("%.3f %.3f %.3f", precision, recall, f1))
+	precision, recall, f1 = Evaluate(nil, pred)
+	expect(t, "0 0 0", fmt.Sprint(precision, recall, f1))
+}
+
+func TestSegmentsFromWords(t *testing.T) {
+	segments := SegmentsFromWords([]string{"中国", "", "hello world", "人口"})
+	expect(t, "中国/ hello world/ 人口/ ", SegmentsToString(segments))
+	expect(t, "中国hello world人口", SegmentsToText(segments, []byte("中国hello world人口")))
+	expect(t, "17 23", fmt.Sprint(segments[2].Start(), segments[2].End()))
+}
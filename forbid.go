@@ -0,0 +1,72 @@
+package sego
+
+// ForbidWord 禁止把text作为一个分词，词典中的该分词在动态规划中被忽略，相当于距离为无穷大
+//
+// 与RemoveWord不同，ForbidWord不修改词典，也不影响载入词典时计算好的子分词，
+// 可以用AllowWord撤销。可以在其他goroutine正在分词时调用。
+func (seg *Segmenter) ForbidWord(text string) {
+	key := seg.wordKey(text)
+	if key == "" {
+		return
+	}
+
+	seg.forbidMutex.Lock()
+	defer seg.forbidMutex.Unlock()
+
+	old := seg.loadForbiddenWords()
+	set := make(wordSet, len(old)+1)
+	for word := range old {
+		set[word] = struct{}{}
+	}
+	set[key] = struct{}{}
+	seg.forbiddenWords.Store(set)
+	seg.cache.clear()
+}
+
+// AllowWord 撤销ForbidWord，重新允许把text作为一个分词
+func (seg *Segmenter) AllowWord(text string) {
+	key := seg.wordKey(text)
+
+	seg.forbidMutex.Lock()
+	defer seg.forbidMutex.Unlock()
+
+	old := seg.loadForbiddenWords()
+	if _, ok := old[key]; !ok {
+		return
+	}
+	set := make(wordSet, len(old))
+	for word := range old {
+		if word != key {
+			set[word] = struct{}{}
+		}
+	}
+	seg.forbiddenWords.Store(set)
+	seg.cache.clear()
+}
+
+// 返回词语在禁用词集合中的键，保留大小写时按小写处理
+func (seg *Segmenter) wordKey(text string) string {
+	key := textSliceToBytes(seg.splitText([]byte(text)))
+	if seg.split.preserveCase {
+		key = foldCase(key)
+	}
+	return string(key)
+}
+
+// 当前禁止作为分词结果的词语集合
+func (seg *Segmenter) loadForbiddenWords() wordSet {
+	set, _ := seg.forbiddenWords.Load().(wordSet)
+	return set
+}
+
+// 删除tokens中被禁用的分词，保持其余分词的先后顺序，返回剩余的分词数
+func removeForbiddenTokens(tokens []*Token, forbidden wordSet) int {
+	numTokens := 0
+	for _, token := range tokens {
+		if !forbidden.containsToken(token) {
+			tokens[numTokens] = token
+			numTokens++
+		}
+	}
+	return numTokens
+}
@@ -0,0 +1,30 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestForbidWord(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	numTokens := seg.Dictionary().NumTokens()
+	seg.ForbidWord("十三亿")
+	seg.ForbidWord("中国")
+	expect(t, "中/p1 国有/p9 十三/p10 亿/p5 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 禁用单字时补加伪分词
+	seg.ForbidWord("亿")
+	expect(t, "中/p1 国有/p9 十三/p10 亿/x 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 不影响词典
+	expect(t, fmt.Sprint(numTokens), seg.Dictionary().NumTokens())
+
+	seg.AllowWord("十三亿")
+	seg.AllowWord("中国")
+	seg.AllowWord("亿")
+	seg.AllowWord("不存在")
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+}
@@ -0,0 +1,56 @@
+package sego
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SetGlueChars 设置连接字符，chars中的每个字符都是一个连接字符，为空时不连接，默认为空
+//
+// 连接字符及其前后紧邻的两个分词合并为一个分词，连续的"甲·乙·丙"合并为一个整体，
+// 比如设置为"·"时"弗拉基米尔·普京"成为一个分词（假设词典中有"弗拉基米尔"和"普京"，
+// 否则只合并"·"前后的单字）。连接字符位于文本首尾、连续出现或者与前后的分词之间
+// 有空白时不合并。合并得到的分词不在词典中，没有词性和子分词。需要在分词前设置。
+func (seg *Segmenter) SetGlueChars(chars string) {
+	seg.glueChars = chars
+}
+
+// 分词是否为一个连接字符
+func (seg *Segmenter) isGlue(segment *Segment) bool {
+	if len(segment.token.text) != 1 {
+		return false
+	}
+	r, size := utf8.DecodeRune(segment.token.text[0])
+	return size == len(segment.token.text[0]) && strings.ContainsRune(seg.glueChars, r)
+}
+
+// 原地合并由连接字符相连的分词，segs按起始位置排列，返回合并后的分词
+func (seg *Segmenter) glueSegments(segs []Segment) []Segment {
+	output := segs[:0]
+	for i := 0; i < len(segs); i++ {
+		// 找出从segs[i]开始由连接字符相连的最长一串分词，连接字符两侧都不能是连接字符
+		last := i
+		for last+2 < len(segs) && seg.isGlue(&segs[last+1]) &&
+			!seg.isGlue(&segs[last]) && !seg.isGlue(&segs[last+2]) &&
+			segs[last].end == segs[last+1].start && segs[last+1].end == segs[last+2].start {
+			last += 2
+		}
+		if last == i {
+			output = append(output, segs[i])
+			continue
+		}
+
+		var text []Text
+		for j := i; j <= last; j++ {
+			text = append(text, segs[j].token.text...)
+		}
+		output = append(output, Segment{
+			start:      segs[i].start,
+			end:        segs[last].end,
+			token:      &Token{text: text, frequency: 1, weight: 1},
+			spaceAfter: segs[last].spaceAfter,
+		})
+		i = last
+	}
+	return output
+}
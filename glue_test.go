@@ -0,0 +1,26 @@
+package sego
+
+import "testing"
+
+func TestSetGlueChars(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddWord("弗拉基米尔", 10, "nr")
+	seg.AddWord("普京", 10, "nr")
+
+	text := []byte("弗拉基米尔·普京访问中国")
+	expect(t, "弗拉基米尔/nr ·/x 普京/nr 访/x 问/x 中国/ ", SegmentsToString(seg.Segment(text)))
+
+	seg.SetGlueChars("·•")
+	segments := seg.Segment(text)
+	expect(t, "弗拉基米尔·普京/ 访/x 问/x 中国/ ", SegmentsToString(segments))
+	expect(t, "弗拉基米尔·普京", string(text[segments[0].Start():segments[0].End()]))
+
+	// 连续的连接字符合并为一个整体
+	expect(t, "中国•人口·有/ ", SegmentsToString(seg.Segment([]byte("中国•人口·有"))))
+
+	// 在文本首尾、与分词之间有空白或者连续出现时不合并
+	expect(t, "·/x 中国/ ·/x ", SegmentsToString(seg.Segment([]byte("·中国·"))))
+	expect(t, "中国/ ·/x 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国 · 人口"))))
+	expect(t, "中国/ ·/x ·/x 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国··人口"))))
+}
@@ -7,4 +7,5 @@ require (
 	github.com/adamzy/sego v0.0.0-20151004184924-5eab9a44f8e8
 	github.com/issue9/assert v1.3.4
 	github.com/pickjunk/brick v1.0.4
+	golang.org/x/text v0.3.3
 )
@@ -0,0 +1,46 @@
+package sego
+
+// 启用的词组名称集合，为nil时启用所有词组
+type groupSet map[string]struct{}
+
+// SetEnabledGroups 只启用groups中的词组，其余标记了词组的分词不再参与分词
+//
+// 词典中的分词可以在末尾用"@"加词组名标记所属的词组（见LoadDictionary），比如把各个
+// 客户专用的词汇放在同一个词典文件里，分别标记为"@客户甲"、"@客户乙"，载入一次后为
+// 每个客户的分词器（见Clone）启用不同的词组，不必重新解析词典。没有标记词组的分词
+// 始终启用；默认启用所有词组，groups为空时只保留没有标记词组的分词。
+//
+// 与ForbidWord一样，未启用的分词在动态规划中被忽略，但不修改词典，也不影响载入词典时
+// 计算好的子分词和同义词。可以在其他goroutine正在分词时调用。
+func (seg *Segmenter) SetEnabledGroups(groups []string) {
+	set := make(groupSet, len(groups))
+	for _, group := range groups {
+		set[group] = struct{}{}
+	}
+	seg.enabledGroups.Store(set)
+	seg.cache.clear()
+}
+
+// EnableAllGroups 撤销SetEnabledGroups，重新启用所有词组
+func (seg *Segmenter) EnableAllGroups() {
+	seg.enabledGroups.Store(groupSet(nil))
+	seg.cache.clear()
+}
+
+// 当前启用的词组，为nil时启用所有词组
+func (seg *Segmenter) loadEnabledGroups() groupSet {
+	set, _ := seg.enabledGroups.Load().(groupSet)
+	return set
+}
+
+// 删除tokens中词组未启用的分词，保持其余分词的先后顺序，返回剩余的分词数
+func removeDisabledTokens(tokens []*Token, groups groupSet) int {
+	numTokens := 0
+	for _, token := range tokens {
+		if _, ok := groups[token.group]; ok || token.group == "" {
+			tokens[numTokens] = token
+			numTokens++
+		}
+	}
+	return numTokens
+}
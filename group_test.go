@@ -0,0 +1,39 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetEnabledGroups(t *testing.T) {
+	var base Segmenter
+	base.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg, parseErrors := base.Overlay(strings.NewReader(
+		"十三亿人口 8 n @甲\n中国有 8 l @乙\n\"web 2.0\" 10 nz @乙|网页 10 n\nhello world 10 @甲\n"))
+	expect(t, "[]", parseErrors)
+
+	// 默认启用所有词组
+	text := []byte("中国有十三亿人口web 2.0 hello world")
+	expect(t, "中国有/l 十三亿人口/n web 2 .0/nz hello world/ ", SegmentsToString(seg.Segment(text)))
+	expect(t, "乙", seg.Segment(text)[0].Token().Group())
+
+	seg.SetEnabledGroups([]string{"甲"})
+	expect(t, "中国/ 有/p3 十三亿人口/n web/x 2/x ./x 0/x hello world/ ", SegmentsToString(seg.Segment(text)))
+
+	// 没有标记词组的分词始终启用
+	seg.SetEnabledGroups(nil)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 web/x 2/x ./x 0/x hello/x world/x ",
+		SegmentsToString(seg.Segment(text)))
+
+	// 副本复制启用的词组，之后单独设置
+	clone := seg.Clone()
+	clone.EnableAllGroups()
+	expect(t, "中国有/l 十三亿人口/n web 2 .0/nz hello world/ ", SegmentsToString(clone.Segment(text)))
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 web/x 2/x ./x 0/x hello/x world/x ",
+		SegmentsToString(seg.Segment(text)))
+
+	// 写回词典文件时保留词组
+	var output strings.Builder
+	expect(t, "<nil>", seg.Dictionary().WriteText(&output))
+	expect(t, "十三亿人口 8 n @甲\n中国有 8 l @乙\nweb 2.0 10 nz @乙|网页 10 n\nhello world 10 @甲\n", output.String())
+}
@@ -0,0 +1,187 @@
+package sego
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// TextRank的参数，见ExtractKeywordsTextRank
+const (
+	// 默认的共现窗口大小
+	defaultTextRankWindow = 5
+
+	// PageRank的阻尼系数
+	textRankDamping = 0.85
+
+	// 最多迭代的次数，以及各词权重的变化都小于该值时提前结束
+	textRankIterations = 100
+	textRankTolerance  = 1e-6
+)
+
+// Keyword 从文本中提取的关键词，见ExtractKeywords和ExtractKeywordsTextRank
+type Keyword struct {
+	// 关键词文本
+	Text string
+
+	// 关键词的词性
+	Pos string
+
+	// 关键词在文本中出现的次数
+	Count int
+
+	// 关键词的权重，越大越重要
+	Weight float64
+}
+
+// ExtractKeywords 按TF-IDF提取文本中最重要的topK个关键词，topK不是正数时返回全部
+//
+// 关键词的权重为它在文本中出现的次数乘以它的路径值（即词频在词典中所占比例的负对数，
+// 见Token结构体的注释），词典中越少见、文本中越常出现的词越重要。只有词典中的分词
+// 才是候选，未登录字元（比如标点）和停用词不参与提取。posPrefixes不为空时只保留
+// 词性以其中某个前缀开头的分词，分词有多个词性时任意一个匹配即可，比如
+//	seg.ExtractKeywords(text, 10, []string{"n", "v"})
+// 只从名词（包括nr、ns、nt等）和动词中提取。结果按权重从高到低排列，权重相同时
+// 按在文本中第一次出现的先后排列。
+func (seg *Segmenter) ExtractKeywords(bytes []byte, topK int, posPrefixes []string) []Keyword {
+	var keywords []Keyword
+	index := make(map[string]int)
+	for _, segment := range seg.Segment(bytes) {
+		token := segment.token
+		if segment.stop || !token.inDictionary || !hasPosPrefix(token, posPrefixes) {
+			continue
+		}
+		text := token.Text()
+		i, ok := index[text]
+		if !ok {
+			i = len(keywords)
+			index[text] = i
+			keywords = append(keywords, Keyword{Text: text, Pos: token.pos})
+		}
+		keywords[i].Count++
+		keywords[i].Weight += float64(token.distance)
+	}
+
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return keywords[i].Weight > keywords[j].Weight
+	})
+	if topK > 0 && len(keywords) > topK {
+		keywords = keywords[:topK]
+	}
+	return keywords
+}
+
+// ExtractKeywordsTextRank 按TextRank提取文本中最重要的topK个关键词，topK不是正数时返回全部
+//
+// 与ExtractKeywords不同，TextRank不使用词典中的词频，只根据词语在文本中的共现关系
+// 计算权重，更适合没有背景语料的单篇文本：Segment结果中相距小于windowSize个分词的两个
+// 候选词之间连一条边，多次共现时边的权重累加，然后在这个图上运行PageRank，与越多重要
+// 的词共现的词越重要。windowSize小于2时使用默认值5。候选词的条件、posPrefixes的含义
+// 以及结果的排列顺序都与ExtractKeywords相同，Weight为PageRank的得分，Count为出现次数。
+func (seg *Segmenter) ExtractKeywordsTextRank(bytes []byte, topK, windowSize int, posPrefixes []string) []Keyword {
+	if windowSize < 2 {
+		windowSize = defaultTextRankWindow
+	}
+
+	// 找出候选词，nodes为每个分词对应的候选词序号，不是候选词时为-1
+	segments := seg.Segment(bytes)
+	var keywords []Keyword
+	index := make(map[string]int)
+	nodes := make([]int, len(segments))
+	for i, segment := range segments {
+		nodes[i] = -1
+		token := segment.token
+		if segment.stop || !token.inDictionary || !hasPosPrefix(token, posPrefixes) {
+			continue
+		}
+		text := token.Text()
+		k, ok := index[text]
+		if !ok {
+			k = len(keywords)
+			index[text] = k
+			keywords = append(keywords, Keyword{Text: text, Pos: token.pos})
+		}
+		keywords[k].Count++
+		nodes[i] = k
+	}
+
+	// 统计窗口内的共现次数作为边的权重，同一个词之间不连边
+	edges := make([]map[int]float64, len(keywords))
+	for i := range edges {
+		edges[i] = make(map[int]float64)
+	}
+	for i, a := range nodes {
+		if a < 0 {
+			continue
+		}
+		for j := i + 1; j < len(nodes) && j < i+windowSize; j++ {
+			if b := nodes[j]; b >= 0 && b != a {
+				edges[a][b]++
+				edges[b][a]++
+			}
+		}
+	}
+
+	// 邻接表按候选词序号排列，保证每次求和的顺序相同
+	type edge struct {
+		node   int
+		weight float64
+	}
+	neighbors := make([][]edge, len(keywords))
+	total := make([]float64, len(keywords))
+	for a, links := range edges {
+		for b, weight := range links {
+			neighbors[a] = append(neighbors[a], edge{b, weight})
+			total[a] += weight
+		}
+		sort.Slice(neighbors[a], func(i, j int) bool { return neighbors[a][i].node < neighbors[a][j].node })
+	}
+
+	// PageRank迭代
+	scores := make([]float64, len(keywords))
+	for i := range scores {
+		scores[i] = 1
+	}
+	next := make([]float64, len(keywords))
+	for iteration := 0; iteration < textRankIterations; iteration++ {
+		change := 0.0
+		for a := range next {
+			sum := 0.0
+			for _, e := range neighbors[a] {
+				sum += e.weight / total[e.node] * scores[e.node]
+			}
+			next[a] = 1 - textRankDamping + textRankDamping*sum
+			change = math.Max(change, math.Abs(next[a]-scores[a]))
+		}
+		scores, next = next, scores
+		if change < textRankTolerance {
+			break
+		}
+	}
+	for i := range keywords {
+		keywords[i].Weight = scores[i]
+	}
+
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return keywords[i].Weight > keywords[j].Weight
+	})
+	if topK > 0 && len(keywords) > topK {
+		keywords = keywords[:topK]
+	}
+	return keywords
+}
+
+// 分词是否有以prefixes中某个前缀开头的词性，prefixes为空时总是返回true
+func hasPosPrefix(token *Token, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, pos := range token.PosList() {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(pos, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
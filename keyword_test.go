@@ -0,0 +1,56 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	keywords := func(list []Keyword) (output string) {
+		for _, keyword := range list {
+			output += fmt.Sprintf("%s/%s*%d(%.2f) ", keyword.Text, keyword.Pos, keyword.Count, keyword.Weight)
+		}
+		return
+	}
+
+	// 标点等未登录字元不是候选
+	text := []byte("中国有十三亿人口，人口")
+	expect(t, "人口/p12*2(10.07) 十三亿/*1(7.03) 中国/*1(4.03) 有/p3*1(3.03) ",
+		keywords(seg.ExtractKeywords(text, 0, nil)))
+	expect(t, "人口/p12*2(10.07) 十三亿/*1(7.03) ", keywords(seg.ExtractKeywords(text, 2, nil)))
+
+	// 按词性前缀过滤，"p1"同时匹配p1和p12
+	expect(t, "人口/p12*2(10.07) ", keywords(seg.ExtractKeywords(text, 0, []string{"p1"})))
+	expect(t, "人口/p12*2(10.07) 有/p3*1(3.03) ", keywords(seg.ExtractKeywords(text, 0, []string{"p12", "p3"})))
+
+	// 停用词不参与提取
+	seg.SetStopWords([]string{"人口"})
+	expect(t, "0", len(seg.ExtractKeywords(text, 0, []string{"p1"})))
+}
+
+func TestExtractKeywordsTextRank(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	keywords := func(list []Keyword) (output string) {
+		for _, keyword := range list {
+			output += fmt.Sprintf("%s/%s*%d(%.2f) ", keyword.Text, keyword.Pos, keyword.Count, keyword.Weight)
+		}
+		return
+	}
+
+	// 与最多的词共现的"人口"最重要，单独出现的词只有基础得分
+	text := []byte("中国人口，中国有人口，十三亿人口")
+	expect(t, "人口/p12*3(1.30) 中国/*2(1.00) 有/p3*1(1.00) 十三亿/*1(0.71) ",
+		keywords(seg.ExtractKeywordsTextRank(text, 0, 0, nil)))
+	expect(t, "人口/p12*3(1.65) 中国/*2(1.11) ", keywords(seg.ExtractKeywordsTextRank(text, 2, 3, nil)))
+	expect(t, "中国/*1(0.15) ", keywords(seg.ExtractKeywordsTextRank([]byte("中国"), 0, 0, nil)))
+
+	// 与ExtractKeywords一样按词性和停用词过滤
+	expect(t, "人口/p12*3(0.15) ", keywords(seg.ExtractKeywordsTextRank(text, 0, 0, []string{"p1"})))
+	seg.SetStopWords([]string{"人口"})
+	expect(t, "中国/*2(1.11) 有/p3*1(1.11) 十三亿/*1(0.78) ", keywords(seg.ExtractKeywordsTextRank(text, 0, 0, nil)))
+}
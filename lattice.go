@@ -0,0 +1,210 @@
+package sego
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Lattice 分词时动态规划的完整网格，用于调试分词结果和调整词典
+type Lattice struct {
+	// 文本划分出的字元
+	Words []string
+
+	// 每个字元处的网格节点，与Words一一对应
+	Nodes []LatticeNode
+
+	// 最终选择的分词，与Segment的结果相同，包括网格之外的各个处理步骤
+	Segments []Segment
+}
+
+// LatticeNode 网格中一个字元处的信息
+type LatticeNode struct {
+	// 以该字元开头的所有候选分词
+	Candidates []LatticeCandidate
+
+	// 从文本开头到该字元（包括该字元）的最短路径值
+	MinDistance float32
+
+	// 最短路径中以该字元结尾的分词
+	Best *Token
+}
+
+// LatticeCandidate 网格中的一个候选分词
+type LatticeCandidate struct {
+	// 候选分词
+	Token *Token
+
+	// 候选分词最后一个字元的序号
+	End int
+
+	// 从文本开头经由该候选分词到达End处的路径值
+	Distance float32
+}
+
+// SegmentDebug 对文本分词，并返回分词过程中动态规划的完整网格
+//
+// 网格记录了每个字元处考虑过的所有候选分词及其路径值，路径值越小越优，
+// 可以用来理解为什么文本被划分成了意料之外的分词。网格是对规范化后的整个文本只用
+// 词典做动态规划的结果，不考虑去掉首尾空白和正则表达式；Segments则与Segment一样经过
+// 所有处理步骤（粘连字符、合并数字、推断未知词性、停用词等），起止位置对应原文本，
+// 设置了这些选项时两者可能不一致。不使用缓存，也不计入分词统计。
+func (seg *Segmenter) SegmentDebug(bytes []byte) *Lattice {
+	text, _ := splitWords(seg.normalize(bytes), seg.split, true, nil, nil)
+
+	lattice := &Lattice{
+		Words: make([]string, len(text)),
+		Nodes: make([]LatticeNode, len(text)),
+	}
+	for i, word := range text {
+		lattice.Words[i] = string(word)
+	}
+
+	forbidden := seg.loadForbiddenWords()
+	sc := &scratch{boosts: seg.loadBoosts(), now: time.Now(), groups: seg.loadEnabledGroups()}
+	jumpers := seg.viterbi(text, false, forbidden, func(current int, token *Token, distance float32) {
+		node := &lattice.Nodes[current]
+		node.Candidates = append(node.Candidates, LatticeCandidate{
+			Token:    token,
+			End:      current + len(token.text) - 1,
+			Distance: distance,
+		})
+	}, sc)
+	for i := range jumpers {
+		lattice.Nodes[i].MinDistance = jumpers[i].minDistance
+		lattice.Nodes[i].Best = jumpers[i].token
+	}
+	lattice.Segments = []Segment{}
+	if !seg.inputTooLong(bytes) {
+		lattice.Segments = seg.appendUncountedSegments(lattice.Segments, bytes, false, false, 0)
+	}
+	if seg.reverseOutput {
+		reverseSegments(lattice.Segments)
+	}
+
+	return lattice
+}
+
+// Candidates 返回文本中每个字元处词典能匹配到的所有分词文本，不做动态规划
+//
+// 返回值与文本划分出的字元一一对应，每个字元处的分词按长度从短到长排列，没有匹配时
+// 为空。这是词典查找的原始结果，不排除禁用词（见ForbidWord），也不受SetMaxCandidates
+// 限制，可以在修改词典前检查词典对文本的覆盖情况。
+func (seg *Segmenter) Candidates(bytes []byte) [][]string {
+	text, _ := splitWords(seg.normalize(bytes), seg.split, false, nil, nil)
+	output := make([][]string, len(text))
+	if seg.dict == nil {
+		return output
+	}
+
+	tokens := make([]*Token, seg.dict.maxTokenLength)
+	for current := range text {
+		numTokens := seg.dict.lookupTokens(
+			text[current:minInt(current+seg.dict.maxTokenLength, len(text))], tokens)
+		output[current] = make([]string, numTokens)
+		for i := 0; i < numTokens; i++ {
+			output[current][i] = tokens[i].Text()
+		}
+	}
+	return output
+}
+
+// LongestAt 返回词典中覆盖文本第byteOffset字节的最长分词，与动态规划选择的分词无关
+//
+// 比如"中国有十三亿人口"中'有'所在位置的最长分词为"国有"。分词长度按字节计算，一样长
+// 时取起始位置靠前的。没有词典中的分词覆盖该位置时第二个返回值为false。与Segment一样，
+// 设置了Unicode规范化时byteOffset和返回分词的起止位置仍是规范化之前的输入中的位置。
+func (seg *Segmenter) LongestAt(bytes []byte, byteOffset int) (Segment, bool) {
+	var longest Segment
+	if seg.dict == nil {
+		return longest, false
+	}
+	text, starts, ends := seg.splitOriginalWords(bytes)
+
+	// 从byteOffset所在或之前的最后一个字元向前查找
+	last := -1
+	for last+1 < len(text) && starts[last+1] <= byteOffset {
+		last++
+	}
+	found := false
+	tokens := make([]*Token, seg.dict.maxTokenLength)
+	for start := maxInt(0, last-seg.dict.maxTokenLength+1); start <= last; start++ {
+		numTokens := seg.dict.lookupTokens(
+			text[start:minInt(start+seg.dict.maxTokenLength, len(text))], tokens)
+		for _, token := range tokens[:numTokens] {
+			end := start + len(token.text) - 1
+			segment := Segment{start: starts[start], end: ends[end], token: token}
+			if segment.end > byteOffset && (!found || segment.end-segment.start > longest.end-longest.start) {
+				longest, found = segment, true
+			}
+		}
+	}
+	return longest, found
+}
+
+// RankedSegment 带有候选分词信息的分词，见SegmentRanked
+type RankedSegment struct {
+	Segment
+
+	// 词典中从该分词的起始位置开始的候选分词数，包括选中的分词
+	Candidates int
+
+	// 选中的分词在候选分词中按字元数从多到少的名次，从1开始，最长的候选分词为1；
+	// 选中的分词不在候选分词中（比如未登录字元的伪分词）时为0
+	LengthRank int
+}
+
+// SegmentRanked 对文本分词，同时返回每个分词起始位置的候选分词数以及选中的分词按长度
+// 的名次
+//
+// 名次大于1说明动态规划没有选择最长的匹配，可以作为分词是否有歧义的简单信号，比
+// SegmentDebug输出完整的网格开销小得多。候选分词包括被ForbidWord禁用的分词。分词的
+// 起止位置与Segment相同，结果总是按从前向后的顺序排列。
+func (seg *Segmenter) SegmentRanked(bytes []byte) []RankedSegment {
+	segments := seg.appendAllSegments(nil, bytes, false, false)
+	ranked := make([]RankedSegment, len(segments))
+	if len(segments) == 0 {
+		return ranked
+	}
+
+	words, offsets, _ := seg.splitOriginalWords(bytes)
+	tokens := make([]*Token, seg.dict.maxTokenLength)
+	for i, segment := range segments {
+		ranked[i].Segment = segment
+		start := sort.SearchInts(offsets, segment.start)
+		if start == len(offsets) || offsets[start] != segment.start {
+			continue
+		}
+
+		numTokens := seg.dict.lookupTokens(words[start:minInt(start+seg.dict.maxTokenLength, len(words))], tokens)
+		ranked[i].Candidates = numTokens
+		for _, token := range tokens[:numTokens] {
+			if token == segment.token {
+				ranked[i].LengthRank = 1
+				for _, other := range tokens[:numTokens] {
+					if len(other.text) > len(token.text) {
+						ranked[i].LengthRank++
+					}
+				}
+				break
+			}
+		}
+	}
+	return ranked
+}
+
+// String 输出网格的文本表示，每个字元一行，格式为
+//	序号 字元 最短路径值 最优分词 | 候选分词/词性(结束序号):路径值 ...
+func (lattice *Lattice) String() string {
+	var output strings.Builder
+	for i, node := range lattice.Nodes {
+		fmt.Fprintf(&output, "%d %s %.2f %s |", i, lattice.Words[i], node.MinDistance, node.Best.Text())
+		for _, candidate := range node.Candidates {
+			fmt.Fprintf(&output, " %s/%s(%d):%.2f",
+				candidate.Token.Text(), candidate.Token.Pos(), candidate.End, candidate.Distance)
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
@@ -0,0 +1,121 @@
+package sego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSegmentDebug(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	lattice := seg.SegmentDebug([]byte("中国有"))
+	expect(t, "[中 国 有]", lattice.Words)
+	expect(t, "中国/ 有/p3 ", SegmentsToString(lattice.Segments))
+	expect(t, "2", len(lattice.Nodes[0].Candidates))
+	expect(t, "中国", lattice.Nodes[1].Best.Text())
+	expect(t, "国有", lattice.Nodes[1].Candidates[1].Token.Text())
+	expect(t, "2", lattice.Nodes[1].Candidates[1].End)
+	expect(t, fmt.Sprint(lattice.Nodes[0].Candidates[0].Distance), lattice.Nodes[0].MinDistance)
+
+	lines := strings.Split(strings.TrimSpace(lattice.String()), "\n")
+	expect(t, "3", len(lines))
+	expect(t, "true", strings.HasPrefix(lines[2], "2 有 "))
+
+	// Segments经过完整的处理，与Segment相同
+	seg.SetTrimSpace(true)
+	seg.SetNormalizeNumbers(true)
+	seg.SetNormalization(NormNFKC)
+	text := []byte(" （十三亿中国 ")
+	expect(t, describeSegments(seg.Segment(text)), describeSegments(seg.SegmentDebug(text).Segments))
+	expect(t, "(/x 1300000000/ 中国/ ", SegmentsToString(seg.SegmentDebug(text).Segments))
+}
+
+func TestCandidates(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	expect(t, "[[中 中国] [国 国有] [有] [十三 十三亿] [三] [亿] [人 人口] [口] []]",
+		seg.Candidates([]byte("中国有十三亿人口！")))
+
+	// 禁用词同样列出
+	seg.ForbidWord("中国")
+	expect(t, "[[中 中国] [国]]", seg.Candidates([]byte("中国")))
+	expect(t, "[]", seg.Candidates(nil))
+
+	var empty Segmenter
+	expect(t, "[[]]", empty.Candidates([]byte("中")))
+}
+
+func TestLongestAt(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	text := []byte("中国有十三亿人口！hello  world")
+	for offset, expected := range map[int]string{
+		0:  "中国",
+		4:  "中国",
+		7:  "国有",
+		9:  "十三亿",
+		17: "十三亿",
+		18: "人口",
+		30: "hello world",
+		35: "hello world",
+	} {
+		segment, ok := seg.LongestAt(text, offset)
+		expect(t, "true", ok)
+		expect(t, expected, segment.Token().Text())
+	}
+
+	segment, _ := seg.LongestAt(text, 7)
+	expect(t, "国有", string(text[segment.Start():segment.End()]))
+	segment, _ = seg.LongestAt(text, 32)
+	expect(t, "hello  world", string(text[segment.Start():segment.End()]))
+
+	// 标点和文本之外的位置没有分词
+	_, ok := seg.LongestAt(text, 24)
+	expect(t, "false", ok)
+	_, ok = seg.LongestAt(text, 100)
+	expect(t, "false", ok)
+
+	// 规范化改变了文本长度时，位置是在输入中的位置
+	seg.SetNormalization(NormNFKC)
+	text = []byte("（中国有")
+	segment, ok = seg.LongestAt(text, 6)
+	expect(t, "true", ok)
+	expect(t, "中国", string(text[segment.Start():segment.End()]))
+	segment, _ = seg.LongestAt(text, 9)
+	expect(t, "国有", string(text[segment.Start():segment.End()]))
+}
+
+func TestSegmentRanked(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	var output string
+	for _, segment := range seg.SegmentRanked([]byte("中国有十三亿人口，")) {
+		output += fmt.Sprintf("%s:%d/%d ", segment.Token().Text(), segment.LengthRank, segment.Candidates)
+	}
+	expect(t, "中国:1/2 有:1/1 十三亿:1/2 人口:1/2 ，:0/0 ", output)
+
+	// 动态规划没有选择最长的匹配
+	seg.AddWord("中国有", 2, "")
+	output = ""
+	for _, segment := range seg.SegmentRanked([]byte("中国有十三亿")) {
+		output += fmt.Sprintf("%s:%d/%d ", segment.Token().Text(), segment.LengthRank, segment.Candidates)
+	}
+	expect(t, "中国:2/3 有:1/1 十三亿:1/2 ", output)
+
+	expect(t, "0", len(seg.SegmentRanked(nil)))
+
+	// 规范化改变了文本长度时，起止位置与Segment相同，候选分词同样能找到
+	seg.SetNormalization(NormNFKC)
+	text := []byte("（中国有十三亿")
+	output = ""
+	for _, segment := range seg.SegmentRanked(text) {
+		output += fmt.Sprintf("%s:%d/%d(%d,%d) ", segment.Token().Text(), segment.LengthRank, segment.Candidates,
+			segment.Start(), segment.End())
+	}
+	expect(t, "(:0/0(0,3) 中国:2/3(3,9) 有:1/1(9,12) 十三亿:1/2(12,21) ", output)
+}
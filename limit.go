@@ -0,0 +1,66 @@
+package sego
+
+import "unicode/utf8"
+
+// SegmentLimit 对文本分词，只返回最前面的maxTokens个分词
+//
+// 动态规划需要完整的路径才能求得最优解，因此这里不是在分词过程中提前停止，而是只对
+// 文本足够长的前缀分词：前缀从maxTokens个最长分词的长度开始，分词数不够时加倍，
+// 直到分出多于maxTokens个分词或者覆盖整个文本。前缀末尾可能被截断的分词总是被丢弃，
+// 但前缀之后的文本无法参与比较，靠近结尾的分词仍可能与对全文调用Segment的结果不同，
+// 适合预览或输入补全等不要求完全一致的场合。
+//
+// 结果总是按从前向后的顺序排列，不受SetReverseOutput影响；maxTokens不是正数时返回nil。
+// 每次调用在分词统计中只计一次，字节数为最后分词的前缀的长度。
+func (seg *Segmenter) SegmentLimit(bytes []byte, maxTokens int) []Segment {
+	if maxTokens <= 0 {
+		return nil
+	}
+
+	size := maxTokens * maxInt(seg.dict.maxTokenLength, 1) * utf8.UTFMax
+	for size < len(bytes) {
+		// 前缀在字符边界处截断
+		cut := size
+		for cut > 0 && !utf8.RuneStart(bytes[cut]) {
+			cut--
+		}
+		if !seg.inputTooLong(bytes[:cut]) {
+			segments := seg.appendUncountedSegments(nil, bytes[:cut], false, false, 0)
+			if len(segments) > maxTokens {
+				seg.stats.add(bytes[:cut])
+				return segments[:maxTokens]
+			}
+		}
+		size *= 2
+	}
+
+	segments := seg.appendAllSegments(nil, bytes, false, false)
+	if len(segments) > maxTokens {
+		segments = segments[:maxTokens]
+	}
+	return segments
+}
+
+// SegmentMaxLen 对文本分词，但忽略词典中长于maxLen个字元的分词，得到比Segment更细的
+// 划分
+//
+// 字元的含义见Token.Text，中文的一个字、英文的一个单词各为一个字元，比如maxLen为2时
+// "十三亿"不再作为一个分词。不修改词典，也不影响载入词典时计算好的子分词，适合在默认
+// 分词之外再生成一组更细的特征。与SegmentWith一样不使用缓存，也不计入分词统计。
+// maxLen不是正数时与Segment相同。
+func (seg *Segmenter) SegmentMaxLen(bytes []byte, maxLen int) []Segment {
+	if maxLen <= 0 {
+		return seg.Segment(bytes)
+	}
+	if seg.inputTooLong(bytes) {
+		return []Segment{}
+	}
+	segments := seg.appendUncountedSegments(nil, bytes, false, false, maxLen)
+	if seg.reverseOutput {
+		reverseSegments(segments)
+	}
+	if segments == nil {
+		return []Segment{}
+	}
+	return segments
+}
@@ -0,0 +1,56 @@
+package sego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSegmentLimit(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte(strings.Repeat("中国有十三亿人口", 100))
+	segments := seg.SegmentLimit(text, 3)
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(segments))
+	expect(t, SegmentsToString(seg.Segment(text)[:10]), SegmentsToString(seg.SegmentLimit(text, 10)))
+
+	// 文本较短时与Segment相同
+	text = []byte("中国有十三亿人口")
+	expect(t, SegmentsToString(seg.Segment(text)), SegmentsToString(seg.SegmentLimit(text, 10)))
+
+	// 不受倒序输出影响
+	seg.SetReverseOutput(true)
+	expect(t, "中国/ 有/p3 ", SegmentsToString(seg.SegmentLimit(text, 2)))
+
+	expect(t, "0", len(seg.SegmentLimit(text, 0)))
+	expect(t, "0", len(seg.SegmentLimit(nil, 3)))
+
+	// 前缀加倍了两次，只在统计中计一次，字节数为最后的前缀的长度
+	seg.ResetStats()
+	text = []byte(strings.Repeat("abcdefghijklmnopqrstuvwxyz ", 300))
+	expect(t, "100", len(seg.SegmentLimit(text, 100)))
+	expect(t, "1", seg.Stats().Calls)
+	expect(t, "4800", seg.Stats().Bytes)
+}
+
+func TestSegmentMaxLen(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+	text := []byte("中国有十三亿人口 hello world")
+
+	expect(t, "中国/ 有/p3 十三/p10 亿/p5 人口/p12 hello world/p1 ", SegmentsToString(seg.SegmentMaxLen(text, 2)))
+	expect(t, "中/p1 国/p2 有/p3 十/x 三/ 亿/p5 人/p6 口/p7 hello/p2 world/p3 ",
+		SegmentsToString(seg.SegmentMaxLen(text, 1)))
+
+	// 不影响分词器本身，maxLen不是正数时与Segment相同
+	expected := SegmentsToString(seg.Segment(text))
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 hello world/p1 ", expected)
+	expect(t, expected, SegmentsToString(seg.SegmentMaxLen(text, 0)))
+	expect(t, expected, SegmentsToString(seg.SegmentMaxLen(text, 3)))
+
+	// 不计入分词统计
+	calls := seg.Stats().Calls
+	seg.SegmentMaxLen(text, 2)
+	expect(t, fmt.Sprint(calls), seg.Stats().Calls)
+}
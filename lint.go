@@ -0,0 +1,59 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// LintDictionary 检查词典内容的格式，返回所有有误的行，没有错误时返回nil
+//
+// 只解析词典，不构建字典树、路径值和同义词，比载入词典快得多，适合在合并词典的修改前
+// 检查。除了LoadDictionaryWithErrors报告的格式错误（比如词频无法解析、缺少词）外，
+// 还报告重复的分词（与载入时相同，只有最先出现的生效）；allowedPos不为空时，还报告
+// 词性不在其中的分词，停用词的词性"__STOP__"总是允许的。返回的错误中File为空。
+func LintDictionary(r io.Reader, allowedPos ...string) []DictParseError {
+	lint := &dictLinter{seen: make(map[string]int)}
+	if len(allowedPos) > 0 {
+		lint.allowedPos = make(map[string]bool, len(allowedPos)+1)
+		for _, pos := range allowedPos {
+			lint.allowedPos[pos] = true
+		}
+		lint.allowedPos["__STOP__"] = true
+	}
+
+	seg := &Segmenter{dict: NewDictionary(), lint: lint}
+	return seg.readDictionary(bufio.NewReader(r), "", 0, DictTextFrequencyPos)
+}
+
+// 检查词典时额外的检查项，为nil时不做检查
+type dictLinter struct {
+	// 已出现的分词及其所在的行号
+	seen map[string]int
+
+	// 允许的词性，为nil时不检查词性
+	allowedPos map[string]bool
+}
+
+// 检查一行中解析出的分词，fail用于记录该行的错误
+func (lint *dictLinter) check(lineNumber int, tokens []*Token, fail func(reason string)) {
+	if lint == nil {
+		return
+	}
+	for _, token := range tokens {
+		key := string(textSliceToBytes(token.text))
+		if line, ok := lint.seen[key]; ok {
+			fail(fmt.Sprintf("重复的分词 %s，与第%d行相同", token.Text(), line))
+		} else {
+			lint.seen[key] = lineNumber
+		}
+
+		if lint.allowedPos != nil {
+			for _, pos := range token.PosList() {
+				if !lint.allowedPos[pos] {
+					fail("未知的词性 " + pos)
+				}
+			}
+		}
+	}
+}
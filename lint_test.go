@@ -0,0 +1,33 @@
+package sego
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLintDictionary(t *testing.T) {
+	dict := strings.Join([]string{
+		"中国 32 ns",
+		"人口 abc n",
+		"",
+		"中国 16 n",
+		"国家 20 n|国度 10 x",
+		"停止 10 __STOP__",
+		" 10 n",
+		"Hello 10 n|hello 5 n",
+	}, "\n")
+
+	expect(t, "[2: 无效的词频 abc 4: 重复的分词 中国，与第1行相同 7: 缺少词频 8: 重复的分词 hello，与第8行相同]",
+		fmt.Sprint(LintDictionary(strings.NewReader(dict))))
+	expect(t, "[2: 无效的词频 abc 4: 重复的分词 中国，与第1行相同 4: 未知的词性 n 5: 未知的词性 n "+
+		"5: 未知的词性 x 7: 缺少词频 8: 未知的词性 n 8: 重复的分词 hello，与第8行相同 8: 未知的词性 n]",
+		fmt.Sprint(LintDictionary(strings.NewReader(dict), "ns")))
+
+	// 测试用的词典没有错误
+	file, err := os.Open("testdata/test_dict1.txt")
+	expect(t, "<nil>", err)
+	defer file.Close()
+	expect(t, "0", len(LintDictionary(file)))
+}
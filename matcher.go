@@ -0,0 +1,172 @@
+package sego
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+)
+
+// Matcher 基于Aho-Corasick自动机的多模式匹配器
+//
+// 与分词不同，Matcher在文本的任意字节位置查找给定的词语，不要求匹配落在分词边界上，
+// 可以用于敏感词检测和屏蔽。匹配按字节精确比较，区分大小写。
+//
+// Matcher创建后只读，可以在多个goroutine中同时使用。
+type Matcher struct {
+	nodes []matcherNode
+	words []string
+}
+
+// 自动机中的一个状态
+type matcherNode struct {
+	// 状态转移
+	next map[byte]int
+
+	// 失配时跳转的状态
+	fail int
+
+	// 以该状态结尾的词语序号，没有时为-1
+	word int
+
+	// 沿失配链找到的下一个以词语结尾的状态，没有时为-1
+	output int
+}
+
+// Match 文本中匹配到的一个词语
+type Match struct {
+	// 词语在文本中的起始字节位置
+	Start int
+
+	// 词语在文本中的结束字节位置（不包括该位置）
+	End int
+
+	// 匹配到的词语
+	Word string
+}
+
+// NewMatcher 由一组词语创建匹配器，空字符串会被忽略
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{nodes: []matcherNode{newMatcherNode()}}
+
+	// 构建字典树
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		state := 0
+		for i := 0; i < len(word); i++ {
+			next, ok := m.nodes[state].next[word[i]]
+			if !ok {
+				next = len(m.nodes)
+				m.nodes = append(m.nodes, newMatcherNode())
+				m.nodes[state].next[word[i]] = next
+			}
+			state = next
+		}
+		if m.nodes[state].word < 0 {
+			m.nodes[state].word = len(m.words)
+			m.words = append(m.words, word)
+		}
+	}
+
+	// 按宽度优先顺序计算失配跳转，保证父状态先于子状态处理
+	queue := []int{}
+	for _, next := range m.nodes[0].next {
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for label, next := range m.nodes[state].next {
+			fail := m.nodes[state].fail
+			for {
+				if target, ok := m.nodes[fail].next[label]; ok {
+					m.nodes[next].fail = target
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+
+			target := m.nodes[next].fail
+			if m.nodes[target].word >= 0 {
+				m.nodes[next].output = target
+			} else {
+				m.nodes[next].output = m.nodes[target].output
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return m
+}
+
+func newMatcherNode() matcherNode {
+	return matcherNode{next: make(map[byte]int), word: -1, output: -1}
+}
+
+// FindAll 找出文本中所有词语的出现位置，包括互相重叠的匹配
+//
+// 结果按起始位置排序，起始位置相同时较长的词语在前。
+func (m *Matcher) FindAll(text []byte) []Match {
+	var matches []Match
+	state := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if next, ok := m.nodes[state].next[text[i]]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = m.nodes[state].fail
+		}
+
+		// 收集以当前字节结尾的所有词语
+		found := state
+		if m.nodes[found].word < 0 {
+			found = m.nodes[found].output
+		}
+		for found > 0 {
+			word := m.words[m.nodes[found].word]
+			matches = append(matches, Match{
+				Start: i + 1 - len(word),
+				End:   i + 1,
+				Word:  word,
+			})
+			found = m.nodes[found].output
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	return matches
+}
+
+// Replace 将文本中匹配到的词语屏蔽，词语中的每个字符都替换为mask
+//
+// 匹配互相重叠时优先屏蔽最靠前、其次最长的词语，比如屏蔽词为"敏感"和"感词"时，
+// Replace([]byte("敏感词"), "*")返回"**词"。
+func (m *Matcher) Replace(text []byte, mask string) []byte {
+	var buf bytes.Buffer
+	last := 0
+	for _, match := range m.FindAll(text) {
+		if match.Start < last {
+			continue
+		}
+		buf.Write(text[last:match.Start])
+		for i := utf8.RuneCount(text[match.Start:match.End]); i > 0; i-- {
+			buf.WriteString(mask)
+		}
+		last = match.End
+	}
+	buf.Write(text[last:])
+	return buf.Bytes()
+}
@@ -0,0 +1,35 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func matchesToString(matches []Match) (output string) {
+	for _, match := range matches {
+		output += fmt.Sprintf("%s[%d:%d] ", match.Word, match.Start, match.End)
+	}
+	return
+}
+
+func TestMatcherFindAll(t *testing.T) {
+	m := NewMatcher([]string{"he", "she", "his", "hers", ""})
+	expect(t, "she[1:4] hers[2:6] he[2:4] ", matchesToString(m.FindAll([]byte("ushers"))))
+	expect(t, "his[0:3] ", matchesToString(m.FindAll([]byte("his"))))
+	expect(t, "", matchesToString(m.FindAll([]byte("HIS"))))
+
+	m = NewMatcher([]string{"敏感", "感词", "敏感词", "词"})
+	expect(t, "敏感词[3:12] 敏感[3:9] 感词[6:12] 词[9:12] ",
+		matchesToString(m.FindAll([]byte("这敏感词"))))
+}
+
+func TestMatcherReplace(t *testing.T) {
+	m := NewMatcher([]string{"敏感", "感词"})
+	expect(t, "**词", string(m.Replace([]byte("敏感词"), "*")))
+	expect(t, "这个**很**", string(m.Replace([]byte("这个敏感很感词"), "*")))
+	expect(t, "没有匹配", string(m.Replace([]byte("没有匹配"), "*")))
+
+	// 最长匹配优先
+	m = NewMatcher([]string{"ab", "abcd", "cd"})
+	expect(t, "x####x##", string(m.Replace([]byte("xabcdxcd"), "#")))
+}
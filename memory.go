@@ -0,0 +1,61 @@
+package sego
+
+import "unsafe"
+
+// ApproxMemoryBytes 估计词典占用的堆内存字节数
+//
+// 累加前缀树的数组、所有分词（包括字元、词性、子分词和同义词数组）以及原始分词组的
+// 大小，不计入底层词典（见Segmenter.Overlay）、各分词共享的词典文件名以及内存分配器
+// 本身的开销，实际占用通常略大于估计值。可以用来比较多个词典的大小或者规划一个进程中
+// 能容纳的词典数量。估计时要遍历整个词典，不宜频繁调用。
+func (dict *Dictionary) ApproxMemoryBytes() int {
+	size := int(unsafe.Sizeof(*dict))
+
+	// 前缀树
+	if dict.trie != nil {
+		trie := dict.trie
+		size += cap(trie.Array)*int(unsafe.Sizeof(trie.Array[0])) +
+			cap(trie.Ninfos)*int(unsafe.Sizeof(trie.Ninfos[0])) +
+			cap(trie.Blocks)*int(unsafe.Sizeof(trie.Blocks[0])) +
+			len(trie.Reject)*int(unsafe.Sizeof(trie.Reject[0]))
+	}
+
+	// 分词，同一个分词可能同时出现在分词数组、原始分词组和其他分词的同义词中，只计一次
+	visited := make(map[*Token]bool)
+	var tokenSize func(token *Token) int
+	tokenSize = func(token *Token) int {
+		if visited[token] {
+			return 0
+		}
+		visited[token] = true
+		size := int(unsafe.Sizeof(*token)) + cap(token.text)*int(unsafe.Sizeof(Text(nil))) +
+			textSliceByteLength(token.text) + len(token.pos) +
+			cap(token.posList)*int(unsafe.Sizeof("")) +
+			cap(token.segments)*int(unsafe.Sizeof((*Segment)(nil))) +
+			len(token.segments)*int(unsafe.Sizeof(Segment{})) +
+			cap(token.synonyms)*int(unsafe.Sizeof((*Token)(nil)))
+		for _, pos := range token.posList {
+			size += len(pos)
+		}
+		for _, segment := range token.segments {
+			size += tokenSize(segment.token)
+		}
+		for _, synonym := range token.synonyms {
+			size += tokenSize(synonym)
+		}
+		return size
+	}
+
+	size += cap(dict.tokens) * int(unsafe.Sizeof((*Token)(nil)))
+	for _, token := range dict.tokens {
+		size += tokenSize(token)
+	}
+	size += cap(dict.groups) * int(unsafe.Sizeof([]*Token(nil)))
+	for _, group := range dict.groups {
+		size += cap(group) * int(unsafe.Sizeof((*Token)(nil)))
+		for _, token := range group {
+			size += tokenSize(token)
+		}
+	}
+	return size
+}
@@ -0,0 +1,27 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApproxMemoryBytes(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	size := seg.Dictionary().ApproxMemoryBytes()
+	expect(t, "true", size > 0)
+
+	// 添加分词后变大
+	seg.AddWord("中华人民共和国", 10, "ns")
+	expect(t, "true", seg.Dictionary().ApproxMemoryBytes() > size)
+
+	// 叠加词典不计入底层词典
+	overlay, _ := seg.Overlay(strings.NewReader("华夏 10 ns\n"))
+	expect(t, "true", overlay.Dictionary().ApproxMemoryBytes() < seg.Dictionary().ApproxMemoryBytes())
+
+	// 通用词典比测试词典大得多
+	loadProdSeg()
+	expect(t, "true", prodSeg.Dictionary().ApproxMemoryBytes() > 1000*size)
+
+	expect(t, "true", NewDictionary().ApproxMemoryBytes() > 0)
+}
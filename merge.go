@@ -0,0 +1,150 @@
+package sego
+
+import "sort"
+
+// ShiftSegments 返回起止位置都加上offset的分词，segs不变
+//
+// 对文本text[offset:]分词后，可以用它把分词的起止位置换算为在整个text中的位置。
+func ShiftSegments(segs []Segment, offset int) []Segment {
+	output := make([]Segment, len(segs))
+	for i, segment := range segs {
+		segment.start += offset
+		segment.end += offset
+		output[i] = segment
+	}
+	return output
+}
+
+// MergeSegments 合并相邻两个窗口的分词结果，a的窗口在windowEnd处结束，b的窗口从
+// windowEnd之前overlap字节处开始
+//
+// text为完整的文本，a和b中分词的起止位置都应是在text中的位置，见ShiftSegments。重叠
+// 部分中两边都没有分词跨过的位置为接缝，第一个接缝之前取a中的分词，最后一个接缝之后
+// 取b中的分词。相邻两个接缝之间两边的划分不同时，取其中最长的分词更长的一边，一样长
+// 时该段在重叠部分中间之前取a、之后取b，因为窗口边缘的分词缺少上下文。此时结果与对
+// 整个文本分词通常相同。重叠部分中没有接缝时，从a中最后一个不晚于重叠部分开头的分词
+// 到b中第一个不早于重叠部分末尾的分词之间的文本重新分词，不会丢失文本。
+func (seg *Segmenter) MergeSegments(text []byte, a, b []Segment, windowEnd, overlap int) []Segment {
+	if len(a) == 0 || len(b) == 0 {
+		return append(append([]Segment{}, a...), b...)
+	}
+
+	// 重叠部分
+	high := windowEnd
+	low := high - overlap
+	middle := low + overlap/2
+	if low < b[0].start {
+		low = b[0].start
+	}
+
+	// 找出重叠部分中所有的接缝
+	var seams []int
+	consider := func(p int) {
+		if p < low || p > high || crossesPosition(a, p) || crossesPosition(b, p) {
+			return
+		}
+		seams = append(seams, p)
+	}
+	for _, segment := range a {
+		consider(segment.end)
+	}
+	for _, segment := range b {
+		consider(segment.start)
+	}
+	if len(seams) > 0 {
+		sort.Ints(seams)
+		output := joinAtSeam(a, nil, seams[0])
+		for i := 1; i < len(seams); i++ {
+			from, to := seams[i-1], seams[i]
+			if from == to {
+				continue
+			}
+			inA, inB := segmentsWithin(a, from, to), segmentsWithin(b, from, to)
+			lenA, lenB := longestSegment(inA), longestSegment(inB)
+			if lenA > lenB || lenA == lenB && from+to < middle*2 {
+				output = append(output, inA...)
+			} else {
+				output = append(output, inB...)
+			}
+		}
+		return append(output, joinAtSeam(nil, b, seams[len(seams)-1])...)
+	}
+
+	// 没有接缝时，a中from之前和b中to之后的分词都不跨过重叠部分，两者之间重新分词
+	from, to := a[0].start, b[len(b)-1].end
+	for _, segment := range a {
+		if segment.start <= low {
+			from = segment.start
+		}
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i].end >= high {
+			to = b[i].end
+		}
+	}
+	if from > to || to > len(text) {
+		return joinAtSeam(a, b, middle)
+	}
+	output := joinAtSeam(a, nil, from)
+	middleStart := len(output)
+	output = seg.appendAllSegments(output, text[from:to], false, false)
+	for i := middleStart; i < len(output); i++ {
+		output[i].start += from
+		output[i].end += from
+		output[i].spaceAfter = isSpaceAt(text, output[i].end)
+	}
+	return append(output, joinAtSeam(nil, b, to)...)
+}
+
+// 返回segs中是否有分词跨过位置p
+func crossesPosition(segs []Segment, p int) bool {
+	for _, segment := range segs {
+		if segment.start < p && p < segment.end {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回segs中完全落在[from, to)中的分词
+func segmentsWithin(segs []Segment, from, to int) []Segment {
+	var output []Segment
+	for _, segment := range segs {
+		if segment.start >= from && segment.end <= to {
+			output = append(output, segment)
+		}
+	}
+	return output
+}
+
+// 返回segs中最长的分词的字节数
+func longestSegment(segs []Segment) int {
+	longest := 0
+	for _, segment := range segs {
+		longest = maxInt(longest, segment.end-segment.start)
+	}
+	return longest
+}
+
+// 返回a中结束位置不超过seam的分词与b中起始位置不小于seam的分词
+func joinAtSeam(a, b []Segment, seam int) []Segment {
+	var output []Segment
+	for _, segment := range a {
+		if segment.end <= seam {
+			output = append(output, segment)
+		}
+	}
+	for _, segment := range b {
+		if segment.start >= seam {
+			output = append(output, segment)
+		}
+	}
+	return output
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,89 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShiftSegments(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国有十三亿人口")
+	segments := ShiftSegments(seg.Segment(text[9:]), 9)
+	expect(t, "十三亿/ 人口/p12 ", SegmentsToString(segments))
+	expect(t, "9", segments[0].Start())
+	expect(t, "十三亿", string(text[segments[0].Start():segments[0].End()]))
+}
+
+func TestMergeSegments(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 两个窗口"中国有十三"和"十三亿人口"重叠"十三"
+	text := []byte("中国有十三亿人口")
+	a := seg.Segment(text[:15])
+	b := ShiftSegments(seg.Segment(text[9:]), 9)
+	expect(t, "中国/ 有/p3 十三/p10 ", SegmentsToString(a))
+	merged := seg.MergeSegments(text, a, b, 15, 6)
+	expect(t, SegmentsToString(seg.Segment(text)), SegmentsToString(merged))
+	expect(t, "18", merged[3].Start())
+
+	// 重叠较多时同样与整个文本的分词结果相同
+	a = seg.Segment(text[:18])
+	b = ShiftSegments(seg.Segment(text[3:]), 3)
+	expect(t, SegmentsToString(seg.Segment(text)), SegmentsToString(seg.MergeSegments(text, a, b, 18, 15)))
+
+	// 某一边为空
+	expect(t, "中国/ 有/p3 十三/p10 ", SegmentsToString(seg.MergeSegments(text, seg.Segment(text[:15]), nil, 15, 6)))
+	expect(t, "十三亿/ 人口/p12 ", SegmentsToString(seg.MergeSegments(text, nil, ShiftSegments(seg.Segment(text[9:]), 9), 0, 0)))
+}
+
+func TestMergeSegmentsPrefersLonger(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 重叠部分为"十三亿"，离中间最近的接缝在"三"之后，但b中跨过该位置的"十三"更长
+	text := []byte("中国有十三亿人口")
+	a := []Segment{
+		{start: 0, end: 6, token: &Token{text: []Text{Text("中"), Text("国")}}},
+		{start: 6, end: 9, token: &Token{text: []Text{Text("有")}}},
+		{start: 9, end: 12, token: &Token{text: []Text{Text("十")}}},
+		{start: 12, end: 15, token: &Token{text: []Text{Text("三")}}},
+		{start: 15, end: 18, token: &Token{text: []Text{Text("亿")}}},
+	}
+	b := []Segment{
+		{start: 9, end: 15, token: &Token{text: []Text{Text("十"), Text("三")}}},
+		{start: 15, end: 18, token: &Token{text: []Text{Text("亿")}}},
+		{start: 18, end: 24, token: &Token{text: []Text{Text("人"), Text("口")}}},
+	}
+	expect(t, "中国/ 有/ 十三/ 亿/ 人口/ ", SegmentsToString(seg.MergeSegments(text, a, b, 18, 9)))
+
+	// 两边一样长时，重叠部分中间之前的一段取a中的分词，之后的取b中的分词
+	b[0] = Segment{start: 9, end: 12, token: &Token{text: []Text{Text("拾")}}}
+	b = append(b[:1], append([]Segment{{start: 12, end: 15, token: &Token{text: []Text{Text("叁")}}}}, b[1:]...)...)
+	expect(t, "中国/ 有/ 十/ 叁/ 亿/ 人口/ ", SegmentsToString(seg.MergeSegments(text, a, b, 18, 9)))
+}
+
+func TestMergeSegmentsWithoutSeam(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 重叠部分"三"被a中的"十三"和b中的"三亿"从两侧跨过，"十三亿"重新分词
+	text := []byte("中国十三亿人口")
+	a := []Segment{
+		{start: 0, end: 6, token: &Token{text: []Text{Text("中"), Text("国")}}},
+		{start: 6, end: 12, token: &Token{text: []Text{Text("十"), Text("三")}}},
+	}
+	b := []Segment{
+		{start: 9, end: 15, token: &Token{text: []Text{Text("三"), Text("亿")}}},
+		{start: 15, end: 21, token: &Token{text: []Text{Text("人"), Text("口")}}},
+	}
+	merged := seg.MergeSegments(text, a, b, 12, 3)
+	expect(t, "中国/ 十三亿/ 人口/ ", SegmentsToString(merged))
+	expect(t, "6 15", fmt.Sprint(merged[1].Start(), " ", merged[1].End()))
+
+	// 重新分词的范围延伸到b中跨过重叠部分的分词末尾
+	b[0] = Segment{start: 9, end: 21, token: &Token{text: []Text{Text("三"), Text("亿"), Text("人"), Text("口")}}}
+	expect(t, "中国/ 十三亿/ 人口/p12 ", SegmentsToString(seg.MergeSegments(text, a, b[:1], 12, 3)))
+}
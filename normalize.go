@@ -0,0 +1,123 @@
+package sego
+
+import (
+	"bytes"
+	"sort"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization 分词前对文本进行的Unicode规范化方式
+//
+// 规范化只做Unicode标准中的等价转换，不包括繁简转换：繁体和简体是不同的字符，需要
+// 繁简对照表，sego没有内置，分词结果也不提供另一种字体的文本。
+type Normalization int
+
+const (
+	// NormNone 不做规范化，默认值
+	NormNone Normalization = iota
+
+	// NormNFC 标准等价合成，比如把"e"加组合重音符合成为"é"
+	NormNFC
+
+	// NormNFKC 兼容等价合成，在NFC的基础上还会把全角字母数字等兼容字符转为半角
+	NormNFKC
+)
+
+// SetNormalization 设置分词前对文本进行的Unicode规范化
+//
+// 在载入词典前设置时，词典中的分词文本也会被同样规范化，以保证两者能够匹配。
+//
+// 规范化可能改变文本的字节长度，比如NFKC把3字节的全角"Ａ"转为1字节的"A"，
+// Segment等返回的分词起止字节位置仍然对应原始输入。一个原字符规范化为多个字符时
+// （比如"ﬁ"转为"fi"），落在其中的分词边界对应到整个原字符的边界上。
+func (seg *Segmenter) SetNormalization(n Normalization) {
+	seg.normalization = n
+}
+
+// 按设置对文本进行规范化
+func (seg *Segmenter) normalize(bytes []byte) []byte {
+	switch seg.normalization {
+	case NormNFC:
+		return norm.NFC.Bytes(bytes)
+	case NormNFKC:
+		return norm.NFKC.Bytes(bytes)
+	}
+	return bytes
+}
+
+// 规范化后文本与原文本之间的位置对应关系
+//
+// 规范化按段进行，normalized和original依次为各段在两个文本中的边界位置，边界处的
+// 位置一一对应，段内的位置没有对应关系。
+type offsetMap struct {
+	normalized []int
+	original   []int
+}
+
+// 按设置对文本进行规范化，同时返回位置对应关系，文本没有变化时为nil
+func (seg *Segmenter) normalizeWithOffsets(text []byte) ([]byte, *offsetMap) {
+	var form norm.Form
+	switch seg.normalization {
+	case NormNFC:
+		form = norm.NFC
+	case NormNFKC:
+		form = norm.NFKC
+	default:
+		return text, nil
+	}
+
+	var iter norm.Iter
+	iter.Init(form, text)
+	normalized := make([]byte, 0, len(text))
+	offsets := &offsetMap{normalized: []int{0}, original: []int{0}}
+	for !iter.Done() {
+		normalized = append(normalized, iter.Next()...)
+
+		// 一个原字符分解为多段时，只有最后一段推进原文本的位置
+		if pos := iter.Pos(); pos > offsets.original[len(offsets.original)-1] {
+			offsets.normalized = append(offsets.normalized, len(normalized))
+			offsets.original = append(offsets.original, pos)
+		}
+	}
+	if bytes.Equal(normalized, text) {
+		return text, nil
+	}
+	offsets.normalized[len(offsets.normalized)-1] = len(normalized)
+	return normalized, offsets
+}
+
+// 规范化后文本中的起始位置对应的原文本位置，位于段内时取所在段的起点
+func (offsets *offsetMap) start(position int) int {
+	i := sort.SearchInts(offsets.normalized, position)
+	if i == len(offsets.normalized) || offsets.normalized[i] > position {
+		i--
+	}
+	return offsets.original[i]
+}
+
+// 规范化后文本中的结束位置对应的原文本位置，位于段内时取所在段的终点
+func (offsets *offsetMap) end(position int) int {
+	i := sort.SearchInts(offsets.normalized, position)
+	if i == len(offsets.normalized) {
+		i--
+	}
+	return offsets.original[i]
+}
+
+// 对规范化后的文本划分字元，同时返回每个字元在原文本中的起止位置，用于把只查找词典的
+// 结果对应到原文本
+func (seg *Segmenter) splitOriginalWords(bytes []byte) ([]Text, []int, []int) {
+	normalized, offsets := seg.normalizeWithOffsets(bytes)
+	words, starts := splitWords(normalized, seg.split, true, nil, nil)
+	ends := make([]int, len(words))
+	for i, word := range words {
+		ends[i] = starts[i] + len(word)
+	}
+	if offsets != nil {
+		for i := range words {
+			starts[i], ends[i] = offsets.start(starts[i]), offsets.end(ends[i])
+		}
+	}
+	return words, starts, ends
+}
@@ -0,0 +1,211 @@
+package sego
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SetNormalizeNumbers 设置是否把数字分词替换为规范形式，默认为false
+//
+// 打开后阿拉伯数字（包括全角数字和小数）以及中文数字（包括大写数字）都替换为十进制
+// 形式的一个分词，比如"3"、"３"、"3.0"、"三"和"叁"都成为"3"，"十三亿"成为
+// "1300000000"，便于统计时合并同一个数。相邻的数字分词先合并为一个，比如被分为
+// "一"、"百"、"零"、"五"的"一百零五"成为"105"，"3.50"成为"3.5"。替换后分词的起止
+// 位置仍对应原文中整个数字，词性取第一个分词的词性；替换得到的分词不在词典中，没有
+// 子分词和同义词。超过10^18的中文数字保持原样。词典中有词性的分词只有词性为数词
+// （以"m"开头）时才作为中文数字，比如词性为"d"的"千万"和词性为"c"的"万一"保持原样；
+// 注意自带词典把这两个词都标为"m"。需要在分词前设置。
+func (seg *Segmenter) SetNormalizeNumbers(normalize bool) {
+	seg.normalizeNumbers = normalize
+}
+
+// 中文数字中的数字和单位
+var (
+	chineseDigits = map[rune]int64{
+		'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+		'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+		'壹': 1, '贰': 2, '叁': 3, '肆': 4, '伍': 5, '陆': 6, '柒': 7, '捌': 8, '玖': 9,
+	}
+	chineseUnits = map[rune]int64{
+		'十': 10, '拾': 10, '百': 100, '佰': 100, '千': 1000, '仟': 1000,
+		'万': 10000, '亿': 100000000,
+	}
+)
+
+// 规范化后中文数字的上限，避免溢出
+const maxChineseNumber = 1000000000000000000
+
+// 原地把数字分词替换为规范形式，segs按起始位置排列，返回替换后的分词
+func (seg *Segmenter) normalizeNumberSegments(segs []Segment) []Segment {
+	output := segs[:0]
+	for i := 0; i < len(segs); {
+		// 找出从segs[i]开始相邻的一串数字分词
+		last, canonical := i, ""
+		if isArabicNumber(segs[i].token) {
+			hasDot := false
+			for last+1 < len(segs) && segs[last].end == segs[last+1].start {
+				if isArabicNumber(segs[last+1].token) {
+					last++
+				} else if !hasDot && segs[last+1].token.Text() == "." && last+2 < len(segs) &&
+					segs[last+1].end == segs[last+2].start && isArabicNumber(segs[last+2].token) {
+					hasDot = true
+					last += 2
+				} else {
+					break
+				}
+			}
+			canonical = canonicalArabicNumber(joinSegmentTexts(segs[i : last+1]))
+		} else if seg.isChineseNumeral(segs[i].token) {
+			for last+1 < len(segs) && segs[last].end == segs[last+1].start &&
+				seg.isChineseNumeral(segs[last+1].token) {
+				last++
+			}
+			if value, ok := parseChineseNumber(joinSegmentTexts(segs[i : last+1])); ok {
+				canonical = strconv.FormatInt(value, 10)
+			}
+		}
+
+		if canonical == "" {
+			output = append(output, segs[i:last+1]...)
+		} else {
+			output = append(output, Segment{
+				start:      segs[i].start,
+				end:        segs[last].end,
+				token:      &Token{text: []Text{Text(canonical)}, frequency: 1, weight: 1, pos: segs[i].token.pos},
+				stop:       segs[i].stop,
+				spaceAfter: segs[last].spaceAfter,
+			})
+		}
+		i = last + 1
+	}
+	return output
+}
+
+// 拼接分词的文本
+func joinSegmentTexts(segs []Segment) string {
+	var text strings.Builder
+	for _, segment := range segs {
+		for _, word := range segment.token.text {
+			text.Write(word)
+		}
+	}
+	return text.String()
+}
+
+// 分词是否只由阿拉伯数字组成，包括全角数字
+func isArabicNumber(token *Token) bool {
+	for _, word := range token.text {
+		for _, r := range string(word) {
+			if !('0' <= r && r <= '9' || '０' <= r && r <= '９') {
+				return false
+			}
+		}
+	}
+	return len(token.text) > 0
+}
+
+// 分词是否只由中文数字和单位组成
+func isChineseNumber(token *Token) bool {
+	for _, word := range token.text {
+		for _, r := range string(word) {
+			if _, ok := chineseDigits[r]; !ok && chineseUnits[r] == 0 {
+				return false
+			}
+		}
+	}
+	return len(token.text) > 0
+}
+
+// 分词是否作为中文数字规范化：只由中文数字和单位组成，并且不在词典中（未登录字元、
+// 模式匹配的分词等），或者在词典中但没有词性或者词性为数词
+func (seg *Segmenter) isChineseNumeral(token *Token) bool {
+	if !isChineseNumber(token) {
+		return false
+	}
+	if token.pos == "" || strings.HasPrefix(token.pos, "m") || seg.dict == nil ||
+		len(token.text) > seg.dict.maxTokenLength {
+		return true
+	}
+	tokens := make([]*Token, len(token.text))
+	numTokens := seg.dict.lookupTokens(token.text, tokens)
+	return numTokens == 0 || tokens[numTokens-1] != token
+}
+
+// 阿拉伯数字的规范形式：全角数字转为半角，去掉整数部分开头和小数部分末尾多余的0
+func canonicalArabicNumber(text string) string {
+	var digits strings.Builder
+	for _, r := range text {
+		if '０' <= r && r <= '９' {
+			r = '0' + r - '０'
+		}
+		digits.WriteRune(r)
+	}
+
+	integer, fraction := digits.String(), ""
+	if dot := strings.IndexByte(integer, '.'); dot >= 0 {
+		integer, fraction = integer[:dot], strings.TrimRight(integer[dot+1:], "0")
+	}
+	if integer = strings.TrimLeft(integer, "0"); integer == "" {
+		integer = "0"
+	}
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
+// 解析中文数字，比如"一百零五"、"十三亿"，没有单位时逐位读出，比如"二〇二四"为2024
+func parseChineseNumber(text string) (int64, bool) {
+	var yi, wan, section, digit int64
+	hasUnit := false
+	for _, r := range text {
+		if d, ok := chineseDigits[r]; ok {
+			if hasUnit {
+				digit = d
+				continue
+			}
+			if section > maxChineseNumber/10 {
+				return 0, false
+			}
+			section = section*10 + d
+			continue
+		}
+
+		if !hasUnit {
+			// 此前逐位读出的数字作为第一个单位前的数字
+			hasUnit, digit, section = true, section, 0
+		}
+		unit := chineseUnits[r]
+		switch unit {
+		case 100000000, 10000:
+			// 亿和万乘以此前所有更小的部分，"万"本身为一万
+			value := section + digit
+			if unit == 100000000 {
+				value += yi + wan
+				yi, wan = 0, 0
+			} else {
+				value += wan
+				wan = 0
+			}
+			if value == 0 {
+				value = 1
+			}
+			if value > maxChineseNumber/unit {
+				return 0, false
+			}
+			if unit == 100000000 {
+				yi = value * unit
+			} else {
+				wan = value * unit
+			}
+			section = 0
+		default:
+			if digit == 0 {
+				digit = 1
+			}
+			section += digit * unit
+		}
+		digit = 0
+	}
+	return yi + wan + section + digit, true
+}
@@ -0,0 +1,61 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetNormalizeNumbers(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("有3.0个三，叁，十三亿中国１２")
+	expect(t, "有/p3 3/x ./x 0/x 个/x 三/ ，/x 叁/x ，/x 十三亿/ 中国/ １/x ２/x ", SegmentsToString(seg.Segment(text)))
+
+	seg.SetNormalizeNumbers(true)
+	segments := seg.Segment(text)
+	expect(t, "有/p3 3/x 个/x 3/ ，/x 3/x ，/x 1300000000/ 中国/ 12/x ", SegmentsToString(segments))
+	expect(t, "3.0", string(text[segments[1].Start():segments[1].End()]))
+	expect(t, "十三亿", string(text[segments[7].Start():segments[7].End()]))
+
+	// 相邻的中文数字合并为一个数
+	expect(t, "105/x ", SegmentsToString(seg.Segment([]byte("一百零五"))))
+
+	// 空白分开的数字不合并
+	expect(t, "3/x 4/x 0.5/x ", SegmentsToString(seg.Segment([]byte("3 4 000.50"))))
+	expect(t, "3/x ./x ", SegmentsToString(seg.Segment([]byte("3."))))
+
+	// 词性不是数词的词典分词不作为数字
+	seg.AddWord("千万", 100, "d")
+	seg.AddWord("万一", 100, "c")
+	seg.AddWord("一千万", 100, "m")
+	expect(t, "千万/d 万一/c ", SegmentsToString(seg.Segment([]byte("千万万一"))))
+	expect(t, "10000000/m ", SegmentsToString(seg.Segment([]byte("一千万"))))
+}
+
+func TestParseChineseNumber(t *testing.T) {
+	for text, expected := range map[string]string{
+		"三":     "3",
+		"十":     "10",
+		"十三":    "13",
+		"二十三":   "23",
+		"一百零五":  "105",
+		"两千零一":  "2001",
+		"一万零五":  "10005",
+		"十三亿":   "1300000000",
+		"一亿三千万": "130000000",
+		"三万亿":   "3000000000000",
+		"叁佰肆拾":  "340",
+		"二〇二四":  "2024",
+		"万":     "10000",
+		"亿亿亿":   "false",
+		"九九九九九九九九九九九九九九九九九九九九": "false",
+	} {
+		value, ok := parseChineseNumber(text)
+		actual := fmt.Sprint(value)
+		if !ok {
+			actual = "false"
+		}
+		expect(t, expected, actual)
+	}
+}
@@ -0,0 +1,94 @@
+package sego
+
+import (
+	"bufio"
+	"io"
+)
+
+// Overlay 返回一个在当前词典之上叠加了reader中分词的新分词器，当前分词器不受影响
+//
+// reader的格式同词典文件，见LoadDictionary，格式有误的行被跳过并在返回值中报告，
+// 其中的文件名为空。叠加的分词优先于当前词典中的同名分词，路径值按当前词典的总权重
+// 计算。新分词器与当前分词器共享词典，只需处理叠加的少量分词，适合为单次请求临时
+// 加入用户自己的词汇；它复制当前分词器的所有设置，可以和当前分词器同时使用。
+func (seg *Segmenter) Overlay(reader io.Reader) (*Segmenter, []DictParseError) {
+	overlay := seg.layered()
+	parseErrors := overlay.readDictionary(bufio.NewReader(reader), "", 0, DictTextFrequencyPos)
+	overlay.Rebuild()
+	return overlay, parseErrors
+}
+
+// Clone 返回一个与当前分词器共享词典的副本，可以为单次请求或者单个租户单独定制
+//
+// 副本在当前词典之上叠加一个空词典，复制开销与词典大小无关。在副本上调用AddWord、
+// Bump等只修改叠加的词典，ForbidWord、SetStopWords等设置也只对副本生效，当前分词器
+// 不受影响，双方可以同时使用。RemoveWord只能删除副本中添加的分词，需要屏蔽共享词典
+// 中的分词时请使用ForbidWord。副本复制当前分词器的所有设置，但不使用缓存，分词统计
+// 单独计算。当前分词器重建词典（包括AddWord等）时会修改共享的分词，不能与副本的分词
+// 同时进行，重建后请重新创建副本。
+func (seg *Segmenter) Clone() *Segmenter {
+	clone := seg.layered()
+	clone.Rebuild()
+	return clone
+}
+
+// SegmentWith 使用词典d而不是分词器自己的词典对文本分词，其余设置不变，d为nil时
+// 相当于空词典
+//
+// 分词器本身不受影响，可以用于偶尔使用专门的小词典分词，比如
+//	var small sego.Segmenter
+//	small.LoadDictionary("专业词典.txt")
+//	segments := seg.SegmentWith(small.Dictionary(), text)
+// d需要是分词器载入或者重建过的词典，分词时不能修改。不使用缓存，也不计入分词统计。
+func (seg *Segmenter) SegmentWith(d *Dictionary, bytes []byte) []Segment {
+	if d == nil {
+		d = NewDictionary()
+	}
+	return seg.withDictionary(d).internalSegment(bytes, false)
+}
+
+// 返回在seg的词典之上叠加了一个空词典的新分词器，尚未重建
+func (seg *Segmenter) layered() *Segmenter {
+	dict := NewDictionary()
+	dict.parent = seg.dict
+	return seg.withDictionary(dict)
+}
+
+// 返回使用词典dict、其余设置与seg相同的新分词器
+func (seg *Segmenter) withDictionary(dict *Dictionary) *Segmenter {
+	derived := &Segmenter{
+		dict:               dict,
+		stopMode:           seg.stopMode,
+		stopFunc:           seg.stopFunc,
+		normalization:      seg.normalization,
+		maxCandidates:      seg.maxCandidates,
+		split:              seg.split,
+		trimSpace:          seg.trimSpace,
+		unknownDistance:    seg.unknownDistance,
+		hasUnknownDistance: seg.hasUnknownDistance,
+		maxInputBytes:      seg.maxInputBytes,
+		skipSingleChars:    seg.skipSingleChars,
+		withoutSynonyms:    seg.withoutSynonyms,
+		patterns:           seg.patterns,
+		integerDistance:    seg.integerDistance,
+		unknownPos:         seg.unknownPos,
+		reverseOutput:      seg.reverseOutput,
+		loadProgress:       seg.loadProgress,
+		glueChars:          seg.glueChars,
+		normalizeNumbers:   seg.normalizeNumbers,
+		splitChars:         seg.splitChars,
+	}
+	if set := seg.loadStopWords(); set != nil {
+		derived.stopWords.Store(set)
+	}
+	if set := seg.loadForbiddenWords(); set != nil {
+		derived.forbiddenWords.Store(set)
+	}
+	if set := seg.loadBoosts(); set != nil {
+		derived.boosts.Store(set)
+	}
+	if set := seg.loadEnabledGroups(); set != nil {
+		derived.enabledGroups.Store(set)
+	}
+	return derived
+}
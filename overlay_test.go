@@ -0,0 +1,66 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	overlay, parseErrors := seg.Overlay(strings.NewReader("有十三亿人口 8 l\n人口 16 n\n有 64 p3|拥有 64 v\n坏行\n"))
+	expect(t, "1", len(parseErrors))
+	expect(t, "4: 缺少词频", parseErrors[0].Error())
+
+	// 叠加的分词优先于底层词典中的同名分词
+	expect(t, "中国/ 有十三亿人口/l ", SegmentsToString(overlay.Segment([]byte("中国有十三亿人口"))))
+	expect(t, "人口/n ", SegmentsToString(overlay.Segment([]byte("人口"))))
+	segments := overlay.Segment([]byte("有"))
+	expect(t, "拥有", segments[0].Token().SynonymsText())
+	expect(t, "拥有/v ", SegmentsToString(overlay.Segment([]byte("拥有"))))
+
+	// 底层分词器不受影响
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 空的叠加词典与底层词典相同
+	empty, _ := seg.Overlay(strings.NewReader(""))
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(empty.Segment([]byte("中国有十三亿人口"))))
+}
+
+func TestClone(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetStopWords([]string{"人口"})
+	text := []byte("中国有十三亿人口")
+
+	clone := seg.Clone()
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(clone.Segment(text)))
+
+	// 副本的修改不影响原分词器
+	clone.AddWord("有十三亿", 100, "l")
+	clone.AddWord("中国", 100, "ns")
+	clone.SetStopWords(nil)
+	clone.ForbidWord("人口")
+	expect(t, "中国/ns 有十三亿/l 人/p6 口/p7 ", SegmentsToString(clone.Segment(text)))
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(seg.Segment(text)))
+	expect(t, "12", seg.Dictionary().NumTokens())
+
+	// 只能删除副本中添加的分词
+	clone.RemoveWord("中国")
+	clone.RemoveWord("十三亿")
+	expect(t, "中国/ 有十三亿/l 人/p6 口/p7 ", SegmentsToString(clone.Segment(text)))
+}
+
+func TestSegmentWith(t *testing.T) {
+	var seg, small Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetStopWords([]string{"有"})
+	small.LoadDictionary("testdata/test_user.txt")
+
+	// 使用另一个词典，其余设置不变
+	expect(t, "甲乙/u 丙/u 中/x 国/x ", SegmentsToString(seg.SegmentWith(small.Dictionary(), []byte("甲乙丙中国有"))))
+	expect(t, "中/x 国/x ", SegmentsToString(seg.SegmentWith(nil, []byte("中国有"))))
+	expect(t, "中国/ 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+	expect(t, "1", seg.Stats().Calls)
+}
@@ -0,0 +1,96 @@
+package sego
+
+import (
+	"regexp"
+	"sort"
+)
+
+// 分词前识别的模式
+type pattern struct {
+	name   string
+	regexp *regexp.Regexp
+	pos    string
+}
+
+// 模式在文本中的一个匹配
+type patternMatch struct {
+	start, end int
+	pattern    *pattern
+}
+
+// AddPattern 添加一个分词前识别的模式，比如电话号码、日期、车牌号
+//
+// 分词时先在文本中查找所有模式的匹配，匹配到的文本作为一个整体成为分词，词性为pos，
+// pos为空时使用name；其余文本照常分词。多个匹配互相重叠时保留起始位置最靠前的，
+// 起始位置相同时保留最长的，一样长时保留先添加的模式。模式按字节匹配规范化后的文本
+// （见SetNormalization），注意英文字母此时尚未转为小写，匹配得到的分词保留原文的
+// 大小写。添加同名的模式会替换原有的模式。需要在分词前设置。
+func (seg *Segmenter) AddPattern(name string, re *regexp.Regexp, pos string) {
+	if pos == "" {
+		pos = name
+	}
+	patterns := make([]pattern, 0, len(seg.patterns)+1)
+	for _, p := range seg.patterns {
+		if p.name != name {
+			patterns = append(patterns, p)
+		}
+	}
+	seg.patterns = append(patterns, pattern{name: name, regexp: re, pos: pos})
+}
+
+// 对已规范化的文本分词，模式的匹配作为整体成为分词，其余部分照常分词
+func (seg *Segmenter) appendPatternSegments(dst []Segment, text []byte, offset int, searchMode bool,
+	forbidden wordSet, sc *scratch) []Segment {
+	position := 0
+	for _, match := range findPatterns(text, seg.patterns) {
+		if position < match.start {
+			dst = seg.appendTextSegments(dst, text[position:match.start], offset+position,
+				searchMode, forbidden, sc)
+		}
+		dst = append(dst, Segment{
+			start: offset + match.start,
+			end:   offset + match.end,
+			token: &Token{
+				text:      []Text{text[match.start:match.end]},
+				frequency: 1,
+				weight:    1,
+				pos:       match.pattern.pos,
+			},
+		})
+		position = match.end
+	}
+	if position < len(text) {
+		dst = seg.appendTextSegments(dst, text[position:], offset+position, searchMode, forbidden, sc)
+	}
+	return dst
+}
+
+// 找出patterns在text中互不重叠的匹配，按起始位置排序，空匹配被忽略
+func findPatterns(text []byte, patterns []pattern) []patternMatch {
+	var matches []patternMatch
+	for i := range patterns {
+		for _, loc := range patterns[i].regexp.FindAllIndex(text, -1) {
+			if loc[1] > loc[0] {
+				matches = append(matches, patternMatch{start: loc[0], end: loc[1], pattern: &patterns[i]})
+			}
+		}
+	}
+
+	// 起始位置相同时较长的在前，一样长时先添加的模式在前
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	selected := matches[:0]
+	end := 0
+	for _, match := range matches {
+		if match.start >= end {
+			selected = append(selected, match)
+			end = match.end
+		}
+	}
+	return selected
+}
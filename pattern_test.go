@@ -0,0 +1,54 @@
+package sego
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAddPattern(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddPattern("phone", regexp.MustCompile(`1\d{10}`), "")
+	seg.AddPattern("date", regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), "t")
+
+	text := []byte("中国人口13800138000有2020-01-02")
+	segments := seg.Segment(text)
+	expect(t, "中国/ 人口/p12 13800138000/phone 有/p3 2020-01-02/t ", SegmentsToString(segments))
+	expect(t, "13800138000", string(text[segments[2].Start():segments[2].End()]))
+	expect(t, "false", segments[2].Token().InDictionary())
+
+	// 全分词不会拆开匹配到的分词
+	expect(t, "中/p1 国/p2 中国/ 人/p6 口/p7 人口/p12 13800138000/phone 有/p3 2020-01-02/t ",
+		SegmentsToString(seg.FullSegment(text)))
+
+	// 同名的模式被替换
+	seg.AddPattern("date", regexp.MustCompile(`\d{4}年`), "t")
+	expect(t, "2020年/t 有/p3 ", SegmentsToString(seg.Segment([]byte("2020年有"))))
+	expect(t, "2020/x -/x 01/x -/x 02/x ", SegmentsToString(seg.Segment([]byte("2020-01-02"))))
+}
+
+func TestAddPatternOverlapping(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddPattern("short", regexp.MustCompile(`AB`), "")
+	seg.AddPattern("long", regexp.MustCompile(`ABC`), "")
+	seg.AddPattern("later", regexp.MustCompile(`BCD`), "")
+
+	// 起始位置最靠前的匹配中最长的优先，重叠的其余匹配被丢弃
+	expect(t, "abc/x 有/p3 ABC/long 人口/p12 ", SegmentsToString(seg.Segment([]byte("abc有ABC人口"))))
+	expect(t, "ABC/long d/x ", SegmentsToString(seg.Segment([]byte("ABCD"))))
+	expect(t, "x/x BCD/later ", SegmentsToString(seg.Segment([]byte("xBCD"))))
+}
+
+func TestAddPatternWithOverlay(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddPattern("number", regexp.MustCompile(`\d+`), "m")
+	overlay, _ := seg.Overlay(strings.NewReader(""))
+	seg.AddPattern("number", regexp.MustCompile(`\d{3}`), "m")
+
+	// Overlay之后的修改不影响叠加的分词器
+	expect(t, "12345/m 人口/p12 ", SegmentsToString(overlay.Segment([]byte("12345人口"))))
+	expect(t, "123/m 45/x 人口/p12 ", SegmentsToString(seg.Segment([]byte("12345人口"))))
+}
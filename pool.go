@@ -0,0 +1,43 @@
+package sego
+
+import "runtime"
+
+// SegmenterPool 一组共享同一个词典的分词器，每个分词器有自己的缓存和分词统计
+//
+// 分词器本身可以在多个goroutine中同时使用，分词使用的临时空间已经在所有分词器之间
+// 复用，所以池并不能让分词更快：池中的分词器由Clone创建，查找分词时要先经过叠加的
+// 空词典，Get和Put也有少量开销，BenchmarkSegmenterPool与BenchmarkSharedSegmenter
+// 对比时两者相近，并发度高时池略慢。大多数情况下共享一个分词器即可，只有在需要每个
+// 分词器单独开启缓存、单独统计，或者在取出的分词器上临时修改设置时才使用池。
+type SegmenterPool struct {
+	segmenters chan *Segmenter
+}
+
+// NewSegmenterPool 创建有n个分词器的池，n不是正数时为GOMAXPROCS
+//
+// 池中的分词器复制seg的所有设置；seg开启了缓存时，每个分词器各自开启同样大小的缓存。
+// 创建后seg不能再重建词典（包括AddWord等），见Clone。
+func NewSegmenterPool(seg *Segmenter, n int) *SegmenterPool {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	pool := &SegmenterPool{segmenters: make(chan *Segmenter, n)}
+	for i := 0; i < n; i++ {
+		clone := seg.Clone()
+		if seg.cache != nil {
+			clone.EnableCache(seg.cache.maxEntries)
+		}
+		pool.segmenters <- clone
+	}
+	return pool
+}
+
+// Get 从池中取出一个分词器，池中没有空闲的分词器时等待其他goroutine放回
+func (pool *SegmenterPool) Get() *Segmenter {
+	return <-pool.segmenters
+}
+
+// Put 把用Get取出的分词器放回池中
+func (pool *SegmenterPool) Put(seg *Segmenter) {
+	pool.segmenters <- seg
+}
@@ -0,0 +1,68 @@
+package sego
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSegmenterPool(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.EnableCache(10)
+	pool := NewSegmenterPool(&seg, 2)
+
+	text := []byte("中国有十三亿人口")
+	expected := SegmentsToString(seg.Segment(text))
+	a, b := pool.Get(), pool.Get()
+	expect(t, "false", a == b)
+	expect(t, "false", a == &seg)
+	expect(t, expected, SegmentsToString(a.Segment(text)))
+
+	// 每个分词器有自己的缓存和统计
+	expect(t, "1", a.cache.len())
+	expect(t, "0", b.cache.len())
+	expect(t, "1", a.Stats().Calls)
+	pool.Put(a)
+	pool.Put(b)
+
+	// 并发使用
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s := pool.Get()
+				if actual := SegmentsToString(s.Segment(text)); actual != expected {
+					t.Errorf("期待值=%q, 实际=%q", expected, actual)
+				}
+				pool.Put(s)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 与BenchmarkSegmenterPool对比并发分词时共享一个分词器和使用池的速度
+func BenchmarkSharedSegmenter(b *testing.B) {
+	lines := loadBenchmarkLines(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			prodSeg.Segment(lines[i%len(lines)])
+		}
+	})
+}
+
+func BenchmarkSegmenterPool(b *testing.B) {
+	lines := loadBenchmarkLines(b)
+	pool := NewSegmenterPool(&prodSeg, 0)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			seg := pool.Get()
+			seg.Segment(lines[i%len(lines)])
+			pool.Put(seg)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package sego
+
+// 载入词典时每隔多少行或者多少个分词报告一次进度
+const loadProgressInterval = 10000
+
+// SetLoadProgress 设置载入词典时报告进度的函数，为nil时不报告，默认为nil
+//
+// LoadDictionary等方法读入词典文件时每读入一万行调用一次progress，linesRead为所有
+// 词典文件中已读入的行数，tokensAdded为已读入的分词数。读完后构建词典（计算子分词和
+// 同义词）同样耗时，这一阶段每构建完一万个分词调用一次，linesRead保持为总行数，
+// tokensAdded为已构建完成的分词数，从0重新计数。载入完成时总是以总行数和词典中的
+// 分词数最后调用一次。progress在载入词典的goroutine中同步调用，应尽快返回。
+func (seg *Segmenter) SetLoadProgress(progress func(linesRead, tokensAdded int)) {
+	seg.loadProgress = progress
+}
+
+// 载入词典的进度，为nil时所有方法都不做任何事
+type loadCounter struct {
+	report func(linesRead, tokensAdded int)
+
+	// 已读入的行数和分词数
+	lines  int
+	tokens int
+
+	// 上次报告的进度，避免连续报告相同的进度
+	lastLines  int
+	lastTokens int
+}
+
+// 开始载入词典，未设置进度函数时返回nil
+func (seg *Segmenter) newLoadCounter() *loadCounter {
+	if seg.loadProgress == nil {
+		return nil
+	}
+	return &loadCounter{report: seg.loadProgress, lastLines: -1}
+}
+
+// 报告进度
+func (c *loadCounter) emit(linesRead, tokensAdded int) {
+	if linesRead != c.lastLines || tokensAdded != c.lastTokens {
+		c.report(linesRead, tokensAdded)
+		c.lastLines, c.lastTokens = linesRead, tokensAdded
+	}
+}
+
+// 读入了一行及其中的numTokens个分词
+func (c *loadCounter) addLine(numTokens int) {
+	if c == nil {
+		return
+	}
+	c.lines++
+	c.tokens += numTokens
+	if c.lines%loadProgressInterval == 0 {
+		c.emit(c.lines, c.tokens)
+	}
+}
+
+// 构建完成了built个分词
+func (c *loadCounter) build(built int) {
+	if c != nil && built%loadProgressInterval == 0 {
+		c.emit(c.lines, built)
+	}
+}
+
+// 载入完成，词典中共有numTokens个分词
+func (c *loadCounter) done(numTokens int) {
+	if c != nil {
+		c.emit(c.lines, numTokens)
+	}
+}
@@ -0,0 +1,42 @@
+package sego
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetLoadProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sego")
+	expect(t, "<nil>", err)
+	defer os.RemoveAll(dir)
+
+	// 两万五千行，每行两个互为同义词的分词
+	var dict bytes.Buffer
+	for i := 0; i < 25000; i++ {
+		fmt.Fprintf(&dict, "甲%d 10|乙%d 10\n", i, i)
+	}
+	file := filepath.Join(dir, "dict.txt")
+	expect(t, "<nil>", ioutil.WriteFile(file, dict.Bytes(), 0644))
+
+	var seg Segmenter
+	var reports []string
+	seg.SetLoadProgress(func(linesRead, tokensAdded int) {
+		reports = append(reports, fmt.Sprintf("%d/%d", linesRead, tokensAdded))
+	})
+	seg.LoadDictionary(file)
+	expect(t, "[10000/20000 20000/40000 25000/10000 25000/20000 25000/30000 25000/40000 25000/50000]",
+		reports)
+
+	// 只在载入词典时报告进度
+	reports = nil
+	seg.AddWord("丙", 10, "")
+	expect(t, "0", len(reports))
+
+	// 行数累计所有词典文件，不足一万行时只在载入完成时报告
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	expect(t, "[12/12]", reports)
+}
@@ -0,0 +1,33 @@
+package sego
+
+// RepeatSegment 合并了重复分词的分词，见SegmentCollapseRepeats
+type RepeatSegment struct {
+	Segment
+
+	// 合并的连续相同分词的个数，未重复的分词为1
+	Count int
+}
+
+// SegmentCollapseRepeats 对文本分词，并把连续相同的分词合并为一个
+//
+// 比如"哈哈哈哈"分为四个"哈"时合并为一个Count为4的"哈"，合并后分词的起止位置覆盖
+// 整段重复的文本。分词的文本相同即视为相同，中间被跳过的空白不影响合并。
+// 适合处理社交网络文本中的叠词和语气词。
+func (seg *Segmenter) SegmentCollapseRepeats(bytes []byte) []RepeatSegment {
+	segments := seg.Segment(bytes)
+	collapsed := make([]RepeatSegment, 0, len(segments))
+	for _, segment := range segments {
+		if n := len(collapsed); n > 0 {
+			last := &collapsed[n-1]
+			if last.token.Text() == segment.token.Text() {
+				// 从后向前输出时后面的分词在前，起止位置取两者的并集
+				last.start = minInt(last.start, segment.start)
+				last.end = maxInt(last.end, segment.end)
+				last.Count++
+				continue
+			}
+		}
+		collapsed = append(collapsed, RepeatSegment{Segment: segment, Count: 1})
+	}
+	return collapsed
+}
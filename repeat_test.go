@@ -0,0 +1,28 @@
+package sego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSegmentCollapseRepeats(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("人口人口人口中国哈哈哈哈，中国")
+	var output string
+	for _, segment := range seg.SegmentCollapseRepeats(text) {
+		output += fmt.Sprintf("%s*%d(%d,%d) ", segment.Token().Text(),
+			segment.Count, segment.Start(), segment.End())
+	}
+	expect(t, "人口*3(0,18) 中国*1(18,24) 哈*4(24,36) ，*1(36,39) 中国*1(39,45) ", output)
+
+	// 倒序输出时起止位置同样覆盖整段重复的文本
+	seg.SetReverseOutput(true)
+	segments := seg.SegmentCollapseRepeats(text)
+	expect(t, "5", len(segments))
+	expect(t, "人口*3(0,18)", fmt.Sprintf("%s*%d(%d,%d)", segments[4].Token().Text(),
+		segments[4].Count, segments[4].Start(), segments[4].End()))
+
+	expect(t, "0", len(seg.SegmentCollapseRepeats(nil)))
+}
@@ -0,0 +1,138 @@
+package sego
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+)
+
+// 重新分词时修改范围两侧至少重新计算的分词数
+const reSegmentContext = 2
+
+// ReSegment 文本修改后只对修改过的部分重新分词，结果与Segment(src)相同
+//
+// prev为修改前文本的Segment结果，src为修改后的完整文本，[changedStart, changedEnd)为
+// src中修改过的范围：在它之前和之后的文本与修改前相同，两者的位置按文本末尾对齐。
+// ReSegment从修改范围向两侧各多取至少reSegmentContext个分词，并继续扩展到没有任何
+// 词典分词跨越的边界。最短路径必然经过这样的边界，边界两侧的分词互不影响，因此边界
+// 之外的分词直接取自prev（修改之后的分词平移起止位置），只有两个边界之间的文本重新
+// 分词。适合编辑器在每次输入后更新分词结果。
+//
+// 浮点路径值的累加有舍入误差，路径值相等的两条路径中选中哪一条取决于累加的起点，
+// 因此只有打开SetIntegerDistance时才能保证结果与整体分词完全相同，否则ReSegment直接
+// 对src整体分词。正则表达式、倒序输出、去掉首尾空白、Unicode规范化、粘连字符、合并
+// 数字、推断未知词性以及临时提升会让上下文的影响超出词典分词的范围，设置了其中任何
+// 一项时同样整体分词；参数不合法或者prev与src对不上时也整体分词。
+func (seg *Segmenter) ReSegment(prev []Segment, src []byte, changedStart, changedEnd int) []Segment {
+	if !seg.canReSegment() || seg.inputTooLong(src) ||
+		changedStart < 0 || changedStart > changedEnd || changedEnd > len(src) {
+		return seg.Segment(src)
+	}
+
+	// 修改之前的分词原样保留，从修改位置向前寻找边界
+	head, start := 0, 0
+	changed := sort.Search(len(prev), func(i int) bool { return prev[i].end > changedStart })
+	for k := changed - maxInt(reSegmentContext, seg.dict.maxTokenLength); k > 0; k-- {
+		if seg.safeCut(src, prev, k, 0) {
+			head, start = k, prev[k].start
+			break
+		}
+	}
+
+	// 修改之后的分词平移shift字节，从修改位置向后寻找边界
+	tail, end, shift := len(prev), len(src), 0
+	if len(prev) > 0 {
+		shift = trimIgnorableEnd(src) - prev[len(prev)-1].end
+	}
+	unchanged := sort.Search(len(prev), func(i int) bool { return prev[i].start >= changedEnd-shift })
+	if changedEnd-shift >= changedStart && unchanged >= head {
+		for k := unchanged + maxInt(reSegmentContext, seg.dict.maxTokenLength); k < len(prev); k++ {
+			if seg.safeCut(src, prev, k, shift) &&
+				seg.sameText(src, prev[k], shift) && seg.sameText(src, prev[len(prev)-1], shift) {
+				tail, end = k, prev[k].start+shift
+				break
+			}
+		}
+	}
+
+	output := make([]Segment, 0, head+len(prev)-tail+reSegmentContext*2)
+	output = append(output, prev[:head]...)
+	middle := len(output)
+	output = seg.appendAllSegments(output, src[start:end], false, false)
+	for i := middle; i < len(output); i++ {
+		output[i].start += start
+		output[i].end += start
+		output[i].spaceAfter = isSpaceAt(src, output[i].end)
+	}
+	for _, segment := range prev[tail:] {
+		segment.start += shift
+		segment.end += shift
+		output = append(output, segment)
+	}
+	return output
+}
+
+// 是否只需要考虑词典分词的上下文，见ReSegment
+func (seg *Segmenter) canReSegment() bool {
+	return seg.dict != nil && seg.integerDistance && len(seg.patterns) == 0 && !seg.reverseOutput && !seg.trimSpace &&
+		seg.normalization == NormNone && seg.glueChars == "" && !seg.normalizeNumbers &&
+		seg.unknownPos == nil && !seg.hasActiveBoosts()
+}
+
+// 判断prev[k]的起始位置平移shift后是否为src中没有词典分词跨越的边界。检查用到的
+// 字元取自prev[k]前后各maxTokenLength个分词，调用方保证这些分词都不在修改范围内，
+// 即检查的文本在修改前后相同，从而边界在修改前后都成立
+func (seg *Segmenter) safeCut(src []byte, prev []Segment, k, shift int) bool {
+	maxLen := maxInt(seg.dict.maxTokenLength, 1)
+	first, last := k-maxLen, k+maxLen-1
+	from, to := 0, len(src)
+	if first >= 0 {
+		from = prev[first].start + shift
+	}
+	if last < len(prev) {
+		to = prev[last].end + shift
+	}
+	cut := prev[k].start + shift
+	if from < 0 || to > len(src) || from > cut || cut > to {
+		return false
+	}
+
+	words, offsets := splitWords(src[from:to], seg.split, true, nil, nil)
+	index := sort.SearchInts(offsets, cut-from)
+	if index == len(offsets) || offsets[index] != cut-from {
+		return false
+	}
+	tokens := make([]*Token, maxLen)
+	for i := maxInt(0, index-maxLen+1); i < index; i++ {
+		numTokens := seg.dict.lookupTokens(words[i:minInt(i+maxLen, len(words))], tokens)
+		for _, token := range tokens[:numTokens] {
+			if i+len(token.text) > index {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// prev中的分词平移shift后是否仍然对应src中相同的文本
+func (seg *Segmenter) sameText(src []byte, segment Segment, shift int) bool {
+	start, end := segment.start+shift, segment.end+shift
+	if start < 0 || end > len(src) || start >= end || !utf8.Valid(src[start:end]) {
+		return false
+	}
+	text := textSliceToBytes(seg.splitText(src[start:end]))
+	return bytes.Equal(foldCase(text), foldCase(textSliceToBytes(segment.token.text)))
+}
+
+// 去掉文本末尾划分字元时丢弃的字元后的长度
+func trimIgnorableEnd(text []byte) int {
+	end := len(text)
+	for end > 0 {
+		_, size := utf8.DecodeLastRune(text[:end])
+		if !isIgnorableWord(text[end-size : end]) {
+			break
+		}
+		end -= size
+	}
+	return end
+}
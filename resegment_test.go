@@ -0,0 +1,103 @@
+package sego
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// 分词的文本、词性、起止位置以及之后是否为空格，用于比较两次分词的结果
+func describeSegments(segments []Segment) string {
+	var output strings.Builder
+	for _, segment := range segments {
+		fmt.Fprintf(&output, "%s/%s %d-%d %v\n", segment.Token().Text(), segment.Token().Pos(),
+			segment.Start(), segment.End(), segment.SpaceAfter())
+	}
+	return output.String()
+}
+
+func TestReSegment(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetIntegerDistance(true)
+
+	// 随机生成文本和修改，结果都与整体分词相同；末尾的停用词被过滤时prev不能直接按
+	// 末尾对齐
+	seg.SetStopWords([]string{"有", "world"})
+	testReSegmentEdits(t, &seg, rand.New(rand.NewSource(1)), 2000)
+	seg.SetStopWords(nil)
+	testReSegmentEdits(t, &seg, rand.New(rand.NewSource(2)), 2000)
+
+	// 使用浮点路径值时整体分词
+	seg.SetIntegerDistance(false)
+	testReSegmentEdits(t, &seg, rand.New(rand.NewSource(3)), 200)
+}
+
+// 文本的组成部分，包括词典中的分词、它们的片段以及词典中没有的字元
+var reSegmentPieces = []string{
+	"中", "国", "有", "三", "亿", "人", "口", "十", "中国", "十三亿", "人口",
+	"丁", "哈", "，", " ", "hello", "world", "ab", "2",
+}
+
+func randomReSegmentText(random *rand.Rand, maxPieces int) string {
+	var text strings.Builder
+	for n := random.Intn(maxPieces + 1); n > 0; n-- {
+		text.WriteString(reSegmentPieces[random.Intn(len(reSegmentPieces))])
+	}
+	return text.String()
+}
+
+// 在字元边界中随机取一个位置
+func randomRuneStart(random *rand.Rand, text string, from int) int {
+	for {
+		i := from + random.Intn(len(text)-from+1)
+		if i == len(text) || utf8.RuneStart(text[i]) {
+			return i
+		}
+	}
+}
+
+func testReSegmentEdits(t *testing.T, seg *Segmenter, random *rand.Rand, rounds int) {
+	for round := 0; round < rounds; round++ {
+		old := randomReSegmentText(random, 60)
+		i := randomRuneStart(random, old, 0)
+		j := randomRuneStart(random, old, i)
+		insert := randomReSegmentText(random, 4)
+
+		prev := seg.Segment([]byte(old))
+		src := []byte(old[:i] + insert + old[j:])
+		expected := describeSegments(seg.Segment(src))
+		actual := describeSegments(seg.ReSegment(prev, src, i, i+len(insert)))
+		if expected != actual {
+			t.Fatalf("%q替换[%d, %d)为%q：期待\n%s实际\n%s", old, i, j, insert, expected, actual)
+		}
+	}
+}
+
+func TestReSegmentReusesSegments(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetIntegerDistance(true)
+
+	// "十"不在词典中，每次分词都会生成新的伪分词，可以据此判断分词是否取自prev
+	old := []byte(strings.Repeat("十中国有人口", 4))
+	prev := seg.Segment(old)
+	src := append(append([]byte{}, old[:36]...), old[39:]...)
+	segments := seg.ReSegment(prev, src, 36, 36)
+	expect(t, describeSegments(seg.Segment(src)), describeSegments(segments))
+	expect(t, "true", segments[0].Token() == prev[0].Token())
+	expect(t, "true", segments[len(segments)-4].Token() == prev[len(prev)-4].Token())
+
+	// 设置了影响上下文的选项时整体分词
+	seg.SetUnknownPosInference(func(prev, cur, next *Segment) string { return "m" })
+	segments = seg.ReSegment(prev, src, 36, 36)
+	expect(t, describeSegments(seg.Segment(src)), describeSegments(segments))
+	expect(t, "false", segments[0].Token() == prev[0].Token())
+
+	// prev与src对不上时同样整体分词
+	seg.SetUnknownPosInference(nil)
+	expect(t, describeSegments(seg.Segment(src)), describeSegments(seg.ReSegment(prev[:3], src, 36, 36)))
+	expect(t, describeSegments(seg.Segment(src)), describeSegments(seg.ReSegment(prev, src, 10, 5)))
+}
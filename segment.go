@@ -1,6 +1,13 @@
 package sego
 
 // Segment 文本中的一个分词
+//
+// 分词的起止位置是输入文本中的字节位置，设置了Unicode规范化时同样对应规范化之前的
+// 原文（见SetNormalization），text[Start():End()]即为分词对应的原文。半角空格只用于
+// 分隔英文单词，不会成为分词，所以分词的起止位置不会落在空格上，比如"  中国  "只有
+// 一个分词"中国"，起止位置为2和8，全角空格、零宽空格和字节顺序标记同样不会成为分词。
+// 制表符、换行等其他空白字符作为未登录字元成为分词，可以用SetTrimSpace去掉文本首尾的
+// 空白字符。
 type Segment struct {
 	// 分词在文本中的起始字节位置
 	start int
@@ -10,6 +17,15 @@ type Segment struct {
 
 	// 分词信息
 	token *Token
+
+	// 是否为停用词，仅在StopMark模式下为true
+	stop bool
+
+	// 原文中分词之后是否紧跟空白字符，见SpaceAfter
+	spaceAfter bool
+
+	// 使用者附加的数据，见SetMeta
+	meta interface{}
 }
 
 // Start 返回分词在文本中的起始字节位置
@@ -26,3 +42,30 @@ func (s *Segment) End() int {
 func (s *Segment) Token() *Token {
 	return s.token
 }
+
+// IsStop 返回该分词是否为停用词，仅在StopMark模式下可能为true
+func (s *Segment) IsStop() bool {
+	return s.stop
+}
+
+// SpaceAfter 返回原文中该分词之后是否紧跟空白字符
+//
+// 半角空格不会成为分词，"new york"和"new-york"中的"new"、"york"无法从分词本身区分，
+// 拼接分词用于显示时可以据此在分词之间补上空格。文本末尾的分词为false，子分词
+// 同样为false，同义词与原分词相同。
+func (s *Segment) SpaceAfter() bool {
+	return s.spaceAfter
+}
+
+// Meta 返回用SetMeta附加在分词上的数据，没有时为nil
+func (s *Segment) Meta() interface{} {
+	return s.meta
+}
+
+// SetMeta 在分词上附加任意数据，比如其他自然语言处理框架中的标注
+//
+// 分词器不会读取或修改附加的数据。复制分词时数据一同复制，比如SegmentsSpread扩展出的
+// 原分词仍带有该数据。
+func (s *Segment) SetMeta(meta interface{}) {
+	s.meta = meta
+}
@@ -2,17 +2,26 @@ package sego
 
 import (
 	"bufio"
+	"errors"
+	"html"
 	"math"
 	"os"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
 	"unicode/utf8"
 )
 
 const (
-	minTokenFrequency = 2 // 仅从字典文件中读取大于等于此频率的分词
+	minTokenFrequency      = 2       // 仅从字典文件中读取大于等于此频率的分词
+	defaultUnknownDistance = 32      // 未登录字元伪分词的默认距离
+	pseudoTokenBlock       = 32      // 每次分配的伪分词数目
+	maxPooledWords         = 1 << 16 // 可以复用的临时空间最多容纳的字元数
+	distanceScale          = 1 << 20 // 整数路径值相对于浮点路径值的倍数，见SetIntegerDistance
 )
 
 const (
@@ -23,13 +32,149 @@ const (
 
 // Segmenter 分词器结构体
 type Segmenter struct {
+	// 分词统计，见Stats。原子操作的64位整数放在结构体开头，以保证在32位平台上对齐
+	stats segmenterStats
+
 	dict *Dictionary
+
+	// 停用词集合，见SetStopWords
+	stopWords atomic.Value
+
+	// 禁止作为分词结果的词语集合，见ForbidWord
+	forbiddenWords atomic.Value
+	forbidMutex    sync.Mutex
+
+	// 临时提升的词语，见BoostWord
+	boosts     atomic.Value
+	boostMutex sync.Mutex
+
+	// 启用的词组，见SetEnabledGroups
+	enabledGroups atomic.Value
+
+	// 停用词的处理方式，见SetStopMode
+	stopMode StopMode
+
+	// 判定停用词的函数，nil时使用Token.IsStop，见SetStopFunc
+	stopFunc func(token *Token) bool
+
+	// 分词前的Unicode规范化方式，见SetNormalization
+	normalization Normalization
+
+	// 每个字元处最多考虑的候选分词数，零表示不限制，见SetMaxCandidates
+	maxCandidates int
+
+	// 划分字元的选项
+	split splitOptions
+
+	// 是否去掉文本首尾的空白字符，见SetTrimSpace
+	trimSpace bool
+
+	// 未登录字元伪分词的距离，见SetUnknownDistance
+	unknownDistance    float32
+	hasUnknownDistance bool
+
+	// 输入文本的最大字节数，零表示不限制，见SetMaxInputBytes
+	maxInputBytes int
+
+	// 全分词时不扩展出单字的子分词，见SetSpreadSingleChars
+	skipSingleChars bool
+
+	// 载入词典时不处理同义词，见SetWithoutSynonyms
+	withoutSynonyms bool
+
+	// 分词前识别的模式，见AddPattern。修改时整体替换，可以与Overlay得到的分词器共享
+	patterns []pattern
+
+	// 分词结果的缓存，nil表示不使用缓存，见EnableCache
+	cache *segmentCache
+
+	// 动态规划使用整数路径值，见SetIntegerDistance
+	integerDistance bool
+
+	// 推断未登录字元词性的函数，见SetUnknownPosInference
+	unknownPos func(prev, cur, next *Segment) string
+
+	// 从后向前输出分词，见SetReverseOutput
+	reverseOutput bool
+
+	// 载入词典时报告进度的函数，见SetLoadProgress
+	loadProgress func(linesRead, tokensAdded int)
+
+	// 正在载入的词典的进度，只在LoadDictionaryWithSchema执行期间不为nil
+	loading *loadCounter
+
+	// 检查词典时的额外检查项，只用于LintDictionary
+	lint *dictLinter
+
+	// 连接字符，见SetGlueChars
+	glueChars string
+
+	// 是否把数字分词替换为规范形式，见SetNormalizeNumbers
+	normalizeNumbers bool
+
+	// 分隔字符，见SetSplitChars
+	splitChars string
 }
 
+// ErrInputTooLong 输入文本超过SetMaxInputBytes设置的长度
+var ErrInputTooLong = errors.New("输入文本超过长度限制")
+
 // 该结构体用于记录Viterbi算法中某字元处的向前分词跳转信息
 type jumper struct {
 	minDistance float32
 	token       *Token
+
+	// 使用整数路径值时的最短路径值，见SetIntegerDistance
+	minIntDistance int64
+}
+
+// 一次分词使用的临时空间，通过scratchPool在多次分词之间复用
+type scratch struct {
+	text    []Text
+	offsets []int
+	jumpers []jumper
+	tokens  []*Token
+	top     []int
+
+	// 本次分词生效的临时提升及其判断时刻，见BoostWord。载入词典时计算子分词使用的
+	// 临时空间中为nil，以免提升影响子分词
+	boosts boostSet
+	now    time.Time
+
+	// 本次分词启用的词组，见SetEnabledGroups，为nil时启用所有词组。载入词典时计算
+	// 子分词使用的临时空间中同样为nil
+	groups groupSet
+
+	// 本次分词中考虑的最长分词的字元数，为0时不限制，见SegmentMaxLen
+	maxMatchLength int
+}
+
+var scratchPool = sync.Pool{
+	New: func() interface{} { return &scratch{} },
+}
+
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+// 归还临时空间，清除其中对文本和分词的引用；太大的临时空间直接丢弃，以免长期占用内存
+func putScratch(sc *scratch) {
+	if cap(sc.text) > maxPooledWords || cap(sc.jumpers) > maxPooledWords {
+		return
+	}
+	for i := range sc.text {
+		sc.text[i] = nil
+	}
+	for i := range sc.jumpers {
+		sc.jumpers[i] = jumper{}
+	}
+	for i := range sc.tokens {
+		sc.tokens[i] = nil
+	}
+	sc.boosts = nil
+	sc.groups = nil
+	sc.maxMatchLength = 0
+	scratchPool.Put(sc)
 }
 
 // Dictionary 返回分词器使用的词典
@@ -41,72 +186,228 @@ func (seg *Segmenter) Dictionary() *Dictionary {
 //
 // 可以载入多个词典文件，文件名用","分隔，排在前面的词典优先载入分词，比如
 // 	"用户词典.txt,通用词典.txt"
-// 当一个分词既出现在用户词典也出现在通用词典中，则优先使用用户词典。
+// 当一个分词既出现在用户词典也出现在通用词典中，则优先使用用户词典：分词的词频、
+// 词性和同义词都以最先载入的一行为准，后面文件中的同名分词被忽略，也不计入词典的
+// 总词频。同一个文件中重复的分词同样以第一行为准。
 //
 // 词典的格式为（每个分词一行）：
 //	分词文本 频率 词性
+// 词性可以有多个，用";"分隔，比如"n;v"，第一个为主词性。
+// 频率可以是整数词频，也可以是带小数点的浮点权重，比如"0.0031"。分词文本中包含
+// 空格、数字等容易与词频、词性混淆的内容时，可以用双引号括起来，比如
+// 	"web 2.0" 100 n
+// 引号中不能再包含双引号，"|"仍需写作"__VERTICAL_BAR__"。每个分词末尾还可以用"@"
+// 加词组名标记它所属的词组，比如"心肌梗死 100 n @医疗"，见SetEnabledGroups。
+//
+// 格式有误的行会被跳过，词典文件无法打开时直接退出程序。需要得到格式错误的详细
+// 信息时请使用LoadDictionaryWithErrors。
 func (seg *Segmenter) LoadDictionary(files string) {
+	if _, err := seg.LoadDictionaryWithErrors(files); err != nil {
+		log.Fatal().Err(err).Msg("无法载入词典文件")
+	}
+}
+
+// LoadDictionaryWithErrors 从文件中载入词典，并返回词典中所有格式有误的行
+//
+// 文件格式和载入规则同LoadDictionary。格式有误的行（比如缺少词频、词频无法解析）
+// 同样会被跳过，但会记录为一个DictParseError；空行和词频低于下限被过滤的行不算错误。
+// 词典文件无法打开时返回error，此时分词器中的词典只载入了一部分，不能用于分词。
+func (seg *Segmenter) LoadDictionaryWithErrors(files string) ([]DictParseError, error) {
+	return seg.LoadDictionaryWithSchema(files, DictTextFrequencyPos)
+}
+
+// DictSchema 词典文件中每个分词的字段顺序
+type DictSchema int
+
+const (
+	// DictTextFrequencyPos 格式为"分词文本 频率 词性"，见LoadDictionary，默认值
+	DictTextFrequencyPos DictSchema = iota
+
+	// DictTextPosFrequency 格式为"分词文本 词性 频率"，词性可以省略
+	DictTextPosFrequency
+)
+
+// LoadDictionaryWithSchema 同LoadDictionaryWithErrors，但词典文件的字段顺序由schema指定
+//
+// 比如第三方词典的格式为"分词文本 词性 频率"时使用DictTextPosFrequency，此时有三个
+// 以上字段的行，最后两个字段分别为词性和频率；包含空格的分词文本需要用双引号括起来，
+// 比如"hello world" n 100。其余规则与默认格式相同。
+func (seg *Segmenter) LoadDictionaryWithSchema(files string, schema DictSchema) ([]DictParseError, error) {
+	var parseErrors []DictParseError
 	seg.dict = NewDictionary()
-	for _, file := range strings.Split(files, ",") {
+	seg.ResetStats()
+	seg.loading = seg.newLoadCounter()
+	defer func() { seg.loading = nil }()
+	for priority, file := range strings.Split(files, ",") {
 		log.Info().Str("file", file).Msg("载入词典")
-		dictFile, err := os.Open(file)
-		defer dictFile.Close()
+		fileErrors, err := seg.readDictionaryFile(file, priority, schema)
+		parseErrors = append(parseErrors, fileErrors...)
 		if err != nil {
-			log.Fatal().Str("file", file).Msg("无法载入词典文件")
+			return parseErrors, err
 		}
+	}
 
-		reader := bufio.NewReader(dictFile)
-		var text string
-		var freqText string
-		var frequency int
-		var pos string
+	seg.Rebuild()
+	seg.loading.done(seg.dict.NumTokens())
 
-		// 逐行读入分词
-		for {
-			line, eof := reader.ReadString('\n')
-			if eof == nil {
-				// 清除末尾的'\n'
-				line = line[:len(line)-1]
-			}
+	log.Info().Msg("词典载入完毕")
+	return parseErrors, nil
+}
 
-			pieces := strings.Split(strings.Trim(line, " "), "|")
-			var synonyms []*Token
-			for _, piece := range pieces {
-				slices := strings.Split(strings.Trim(piece, " "), " ")
-				l := len(slices)
-
-				// 最后一个元素为数字（词频）
-				if regexp.MustCompile("^\\d+$").MatchString(slices[l-1]) {
-					// 格式：[词] [词频]，至少要有两个元素
-					if l < 2 {
-						break
-					}
+// 读入一个词典文件中的分词，读完后立即关闭文件
+func (seg *Segmenter) readDictionaryFile(file string, priority int, schema DictSchema) ([]DictParseError, error) {
+	dictFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer dictFile.Close()
 
-					text = strings.Join(slices[:l-1], " ")
-					freqText = slices[l-1]
-					pos = ""
+	return seg.readDictionary(bufio.NewReader(dictFile), file, priority, schema), nil
+}
+
+// 从reader中逐行读入词典，分词添加到词典的原始分词中，file为报告格式错误时使用的文件名，
+// priority为分词的优先级，见Token结构体的注释，schema为字段顺序
+func (seg *Segmenter) readDictionary(reader *bufio.Reader, file string, priority int,
+	schema DictSchema) (parseErrors []DictParseError) {
+	var text string
+	var freqText string
+	var frequency int
+	var weight float64
+	var pos string
+	groupNames := make(map[string]string)
+
+	// 逐行读入分词
+	for lineNumber := 1; ; lineNumber++ {
+		line, eof := reader.ReadString('\n')
+		if eof == nil {
+			// 清除末尾的'\n'
+			line = line[:len(line)-1]
+		}
+
+		// 记录格式错误
+		fail := func(reason string) {
+			parseErrors = append(parseErrors, DictParseError{File: file, Line: lineNumber, Reason: reason})
+		}
+
+		line = strings.Trim(line, " ")
+		pieces := []string{line}
+		if !seg.withoutSynonyms {
+			// 同一行中用"|"分隔的分词互为同义词
+			pieces = strings.Split(line, "|")
+		}
+		if len(pieces) == 1 && pieces[0] == "" {
+			// 空行
+			pieces = nil
+		}
+		var synonyms []*Token
+		for _, piece := range pieces {
+			piece = strings.Trim(piece, " ")
+
+			// 末尾以"@"开头的字段为词组名，见SetEnabledGroups
+			group := ""
+			if i := strings.LastIndex(piece, " @"); i >= 0 && len(piece) > i+2 &&
+				!strings.Contains(piece[i+2:], " ") {
+				// 同名的词组共用一个字符串，以免每个分词都引用整行文本
+				group = piece[i+2:]
+				if name, ok := groupNames[group]; ok {
+					group = name
 				} else {
-					// 格式：[词] [词频] [词性]，至少要有三个元素
-					if l < 3 {
-						break
+					group = string([]byte(group))
+					groupNames[group] = group
+				}
+				piece = strings.TrimRight(piece[:i], " ")
+			}
+			slices := strings.Split(piece, " ")
+			l := len(slices)
+
+			if strings.HasPrefix(piece, "\"") {
+				// 格式："[词]" [词频] [词性]，引号中的文本原样作为词
+				end := strings.Index(piece[1:], "\"") + 1
+				if end == 0 {
+					fail("引号不匹配")
+					break
+				}
+				fields := strings.Fields(piece[end+1:])
+				if len(fields) == 0 {
+					fail("缺少词频")
+					break
+				}
+				if len(fields) > 2 {
+					fail("多余的字段")
+					break
+				}
+
+				text = strings.Replace(piece[1:end], "__VERTICAL_BAR__", "|", -1)
+				freqText = fields[0]
+				pos = ""
+				if len(fields) == 2 {
+					pos = fields[1]
+					if schema == DictTextPosFrequency {
+						pos, freqText = fields[0], fields[1]
 					}
+				}
+			} else if schema == DictTextPosFrequency {
+				// 格式：[词] [词性] [词频]，词性可以省略
+				if l < 2 {
+					fail("缺少词频")
+					break
+				}
+				freqText = slices[l-1]
+				if !isFrequencyText(freqText) {
+					fail("无效的词频 " + freqText)
+					continue
+				}
 
+				text = slices[0]
+				pos = ""
+				if l > 2 {
 					text = strings.Join(slices[:l-2], " ")
-					// 特殊符号转义
-					text = strings.Replace(text, "__VERTICAL_BAR__", "|", -1)
-					freqText = slices[l-2]
-					pos = slices[l-1]
+					pos = slices[l-2]
+				}
+				text = strings.Replace(text, "__VERTICAL_BAR__", "|", -1)
+			} else if isFrequencyText(slices[l-1]) {
+				// 格式：[词] [词频]，至少要有两个元素
+				if l < 2 {
+					fail("缺少词")
+					break
 				}
 
-				// 词为空，无效行
-				if text == "" {
+				text = strings.Join(slices[:l-1], " ")
+				freqText = slices[l-1]
+				pos = ""
+			} else {
+				// 格式：[词] [词频] [词性]，至少要有三个元素
+				if l < 3 {
+					fail("缺少词频")
 					break
 				}
 
-				// 解析词频
-				var err error
+				text = strings.Join(slices[:l-2], " ")
+				// 特殊符号转义
+				text = strings.Replace(text, "__VERTICAL_BAR__", "|", -1)
+				freqText = slices[l-2]
+				pos = slices[l-1]
+			}
+
+			// 词为空，无效行
+			if text == "" {
+				fail("缺少词")
+				break
+			}
+
+			// 解析词频，带小数点的词频为浮点权重
+			var err error
+			if strings.Contains(freqText, ".") {
+				weight, err = strconv.ParseFloat(freqText, 64)
+				if err != nil || weight <= 0 {
+					fail("无效的权重 " + freqText)
+					continue
+				}
+				frequency = int(weight)
+			} else {
 				frequency, err = strconv.Atoi(freqText)
 				if err != nil {
+					fail("无效的词频 " + freqText)
 					continue
 				}
 
@@ -114,53 +415,137 @@ func (seg *Segmenter) LoadDictionary(files string) {
 				if frequency < minTokenFrequency {
 					continue
 				}
+				weight = float64(frequency)
+			}
 
-				words := splitTextToWords([]byte(text))
-				token := Token{text: words, frequency: frequency, pos: pos}
-
-				// 添加到同义词数组
-				synonyms = append(synonyms, &token)
+			words := seg.splitText([]byte(text))
+			token := Token{
+				text:         words,
+				frequency:    frequency,
+				weight:       weight,
+				inDictionary: true,
+				priority:     priority,
+				source:       file,
+				group:        group,
 			}
+			token.pos, token.posList = parsePos(pos)
 
-			for i, token := range synonyms {
-				token.synonyms = append(token.synonyms, synonyms[:i]...)
-				token.synonyms = append(token.synonyms, synonyms[i+1:]...)
-				// log.Info().Str("synonyms", token.SynonymsText()).Send()
+			// 添加到同义词数组
+			synonyms = append(synonyms, &token)
+		}
 
-				// 将分词添加到字典中
-				seg.dict.addToken(token)
-			}
+		seg.lint.check(lineNumber, synonyms, fail)
 
-			// 文件结束
-			if eof != nil {
-				break
+		// 同一行的分词互为同义词，在Rebuild中添加到字典
+		if len(synonyms) > 0 {
+			seg.dict.groups = append(seg.dict.groups, synonyms)
+		}
+		if eof == nil || line != "" {
+			seg.loading.addLine(len(synonyms))
+		}
+
+		// 文件结束
+		if eof != nil {
+			break
+		}
+	}
+	return
+}
+
+// 文本是否为词典中的词频，即整数或者带小数点的浮点数，相当于正则表达式^\d+(\.\d+)?$
+func isFrequencyText(text string) bool {
+	digits := func(s string) bool {
+		if s == "" {
+			return false
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return false
 			}
 		}
+		return true
+	}
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		return digits(text[:dot]) && digits(text[dot+1:])
 	}
+	return digits(text)
+}
 
-	// 计算每个分词的路径值，路径值含义见Token结构体的注释
-	logTotalFrequency := float32(math.Log2(float64(seg.dict.totalFrequency)))
+// Rebuild 根据词典中的原始分词重新构建词典
+//
+// 重新计算所有分词的路径值、子分词以及由子分词的同义词组合出的同义词。LoadDictionary
+// 载入完毕时会自动调用，使用AddWordDeferred、RemoveWordDeferred批量修改词典后需要
+// 调用一次Rebuild才能生效。Rebuild不能与分词同时进行。
+func (seg *Segmenter) Rebuild() {
+	var groups [][]*Token
+	var parent *Dictionary
+	if seg.dict != nil {
+		groups = seg.dict.groups
+		parent = seg.dict.parent
+	}
+	seg.cache.clear()
+	seg.dict = NewDictionary()
+	seg.dict.groups = groups
+	seg.dict.parent = parent
+	seg.dict.foldCase = seg.split.preserveCase
+	if parent != nil {
+		seg.dict.maxTokenLength = parent.maxTokenLength
+	}
+
+	// 清除上次构建得到的信息，同一行的分词互为同义词
+	for _, group := range groups {
+		for i, token := range group {
+			token.distance = 0
+			token.segments = nil
+			token.synonyms = nil
+			token.synonyms = append(token.synonyms, group[:i]...)
+			token.synonyms = append(token.synonyms, group[i+1:]...)
+
+			// 将分词添加到字典中
+			seg.dict.addToken(token)
+		}
+	}
+
+	// 计算每个分词的路径值，路径值含义见Token结构体的注释。叠加词典的分词按最底层
+	// 词典的总权重计算，以便和底层词典的分词比较
+	root := seg.dict
+	for root.parent != nil {
+		root = root.parent
+	}
+	logTotalWeight := float32(math.Log2(root.totalWeight))
 	for i := range seg.dict.tokens {
 		token := seg.dict.tokens[i]
-		token.distance = logTotalFrequency - float32(math.Log2(float64(token.frequency)))
+		token.distance = logTotalWeight - float32(math.Log2(token.weight))
+		token.intDistance = quantizeDistance(token.distance)
 	}
 
 	// 对每个分词进行细致划分，用于搜索引擎模式，该模式用法见Token结构体的注释。
+	sc := &scratch{}
 	for i := range seg.dict.tokens {
 		token := seg.dict.tokens[i]
 
 		// 子分词
-		segments := seg.segmentWords(token.text, true)
+		segments := seg.subSegments(token.text, sc)
 		for i := 0; i < len(segments); i++ {
 			token.segments = append(token.segments, &segments[i])
 		}
+		seg.loading.build(i + 1)
+		if seg.withoutSynonyms {
+			continue
+		}
 
 		// 找出所有子分词的同义词，按笛卡尔积算出该词的所有同义词
 		synonyms := []*Token{
 			{
-				frequency: token.frequency,
-				distance:  token.distance,
-				pos:       token.pos,
+				frequency:    token.frequency,
+				weight:       token.weight,
+				distance:     token.distance,
+				pos:          token.pos,
+				posList:      token.posList,
+				inDictionary: true,
+				priority:     token.priority,
+				source:       token.source,
+				group:        token.group,
 			},
 		}
 		hasSynonyms := false
@@ -175,10 +560,16 @@ func (seg *Segmenter) LoadDictionary(files string) {
 						text = append(text, a.text...)
 						text = append(text, b.text...)
 						cartesian = append(cartesian, &Token{
-							text:      text,
-							frequency: a.frequency,
-							distance:  a.distance,
-							pos:       a.pos,
+							text:         text,
+							frequency:    a.frequency,
+							weight:       a.weight,
+							distance:     a.distance,
+							pos:          a.pos,
+							posList:      a.posList,
+							inDictionary: true,
+							priority:     a.priority,
+							source:       a.source,
+							group:        a.group,
 						})
 					}
 				} else {
@@ -186,10 +577,16 @@ func (seg *Segmenter) LoadDictionary(files string) {
 					text = append(text, a.text...)
 					text = append(text, segment.token.text...)
 					cartesian = append(cartesian, &Token{
-						text:      text,
-						frequency: a.frequency,
-						distance:  a.distance,
-						pos:       a.pos,
+						text:         text,
+						frequency:    a.frequency,
+						weight:       a.weight,
+						distance:     a.distance,
+						pos:          a.pos,
+						posList:      a.posList,
+						inDictionary: true,
+						priority:     a.priority,
+						source:       a.source,
+						group:        a.group,
 					})
 				}
 			}
@@ -198,11 +595,12 @@ func (seg *Segmenter) LoadDictionary(files string) {
 		}
 
 		if hasSynonyms {
-			token.synonyms = synonyms
+			// 组合出的同义词追加在同一行的同义词之后
+			token.synonyms = append(token.synonyms, synonyms...)
 
-			for i, t := range token.synonyms {
+			for i, t := range synonyms {
 				// 子分词
-				segments := seg.segmentWords(t.text, true)
+				segments := seg.subSegments(t.text, sc)
 				for i := 0; i < len(segments); i++ {
 					t.segments = append(t.segments, &segments[i])
 				}
@@ -217,8 +615,27 @@ func (seg *Segmenter) LoadDictionary(files string) {
 			}
 		}
 	}
+}
 
-	log.Info().Msg("词典载入完毕")
+// 解析词典中的词性字段，返回主词性以及有多个词性时的全部词性
+func parsePos(field string) (string, []string) {
+	if !strings.Contains(field, ";") {
+		return field, nil
+	}
+
+	var posList []string
+	for _, pos := range strings.Split(field, ";") {
+		if pos != "" {
+			posList = append(posList, pos)
+		}
+	}
+	switch len(posList) {
+	case 0:
+		return "", nil
+	case 1:
+		return posList[0], nil
+	}
+	return posList[0], posList
 }
 
 // Segment 对文本分词
@@ -229,9 +646,28 @@ func (seg *Segmenter) LoadDictionary(files string) {
 // 输出：
 //	[]Segment	划分的分词
 func (seg *Segmenter) Segment(bytes []byte) []Segment {
+	if seg.cache != nil && !seg.inputTooLong(bytes) && !seg.hasActiveBoosts() {
+		return seg.cachedSegment(bytes)
+	}
 	return seg.internalSegment(bytes, false)
 }
 
+// SegmentWithError 对文本分词，文本超过SetMaxInputBytes设置的长度时返回ErrInputTooLong
+func (seg *Segmenter) SegmentWithError(bytes []byte) ([]Segment, error) {
+	if seg.inputTooLong(bytes) {
+		return nil, ErrInputTooLong
+	}
+	return seg.Segment(bytes), nil
+}
+
+// SegmentAppend 对文本分词，并把分词追加到dst之后返回，用法同append
+//
+// 结果与Segment相同。反复分词时可以传入上次的结果dst[:0]以复用其内存，配合分词器
+// 内部复用的临时空间，分词短文本时几乎不需要分配内存。
+func (seg *Segmenter) SegmentAppend(dst []Segment, bytes []byte) []Segment {
+	return seg.appendSegments(dst, bytes, false)
+}
+
 // FullSegment 对文本进行全分词
 //
 // 输入参数：
@@ -240,12 +676,129 @@ func (seg *Segmenter) Segment(bytes []byte) []Segment {
 // 输出：
 //	[]Segment	划分的分词
 func (seg *Segmenter) FullSegment(bytes []byte) []Segment {
-	segments := seg.internalSegment(bytes, false)
+	stopWords := seg.loadStopWords()
+	segments := seg.segmentWithStopWords(bytes, stopWords)
 
 	// 分词扩展，扩展出子分词、同义词
-	segments = SegmentsSpread(segments)
+	segments = spread(segments, seg.spreadOptions(nil))
 
-	return segments
+	return seg.filterStopWords(segments, stopWords)
+}
+
+// FullSegmentWithError 对文本进行全分词，文本超过SetMaxInputBytes设置的长度时返回
+// ErrInputTooLong
+func (seg *Segmenter) FullSegmentWithError(bytes []byte) ([]Segment, error) {
+	if seg.inputTooLong(bytes) {
+		return nil, ErrInputTooLong
+	}
+	return seg.FullSegment(bytes), nil
+}
+
+// FullSegmentWithExpanded 对文本进行全分词，同时返回是否扩展出了子分词或同义词
+//
+// 第二个返回值为false时，全分词的结果与Segment(bytes)相同。
+func (seg *Segmenter) FullSegmentWithExpanded(bytes []byte) ([]Segment, bool) {
+	stopWords := seg.loadStopWords()
+	segments := seg.segmentWithStopWords(bytes, stopWords)
+
+	// 扩展保留每个原分词，多出的分词都是扩展得到的
+	expanded := spread(segments, seg.spreadOptions(nil))
+	return seg.filterStopWords(expanded, stopWords), len(expanded) > len(segments)
+}
+
+// FullSegmentIf 对文本进行全分词，只对expand返回true的分词扩展子分词，见SpreadIf
+func (seg *Segmenter) FullSegmentIf(bytes []byte, expand func(*Token) bool) []Segment {
+	stopWords := seg.loadStopWords()
+	segments := seg.segmentWithStopWords(bytes, stopWords)
+	return seg.filterStopWords(spread(segments, seg.spreadOptions(expand)), stopWords)
+}
+
+// SegmentBoth 只做一次动态规划，同时返回普通分词和全分词的结果
+//
+// normal与Segment(bytes)相同，search与FullSegment(bytes)相同，两者共享同一次
+// 分词得到的最短路径，比分别调用Segment和FullSegment少一半计算量。
+func (seg *Segmenter) SegmentBoth(bytes []byte) (normal []Segment, search []Segment) {
+	stopWords := seg.loadStopWords()
+	normal = seg.segmentWithStopWords(bytes, stopWords)
+	search = seg.filterStopWords(spread(normal, seg.spreadOptions(nil)), stopWords)
+	return
+}
+
+// Normalize 对文本分词，并把分词替换为canon中对应的规范形式后拼接为字符串
+//
+// 不在canon中的分词保持原样，拼接方式同Join。比如把同义词替换为主词，可用于
+// 搜索查询的归一化。
+func (seg *Segmenter) Normalize(bytes []byte, canon map[string]string) string {
+	segments := seg.Segment(bytes)
+	words := make([]Text, len(segments))
+	for i, segment := range segments {
+		text := segment.token.Text()
+		if c, ok := canon[text]; ok {
+			text = c
+		}
+		words[i] = Text(text)
+	}
+	return Join(words)
+}
+
+// Highlight 对文本分词，在与targets匹配的分词前后分别插入pre和post，其余文本做HTML转义
+//
+// targets的键与Token.Text()比较，注意英文分词已被转为小写。pre和post原样插入，
+// 不做转义，比如"<mark>"和"</mark>"。返回值可直接嵌入HTML。
+func (seg *Segmenter) Highlight(src []byte, targets map[string]bool, pre, post string) string {
+	var output strings.Builder
+	last := 0
+	for _, segment := range seg.Segment(src) {
+		if !targets[segment.token.Text()] {
+			continue
+		}
+		output.WriteString(html.EscapeString(string(src[last:segment.start])))
+		output.WriteString(pre)
+		output.WriteString(html.EscapeString(string(src[segment.start:segment.end])))
+		output.WriteString(post)
+		last = segment.end
+	}
+	output.WriteString(html.EscapeString(string(src[last:])))
+	return output.String()
+}
+
+// SegmentBatch 使用固定数目的goroutine对多段文本并行分词
+//
+// 输入参数：
+//	texts	多段UTF8文本的字节数组
+//	workers	并行分词的goroutine数目，小于等于零时使用CPU数
+//
+// 输出：
+//	[][]Segment	每段文本划分的分词，顺序与texts一致
+func (seg *Segmenter) SegmentBatch(texts [][]byte, workers int) [][]Segment {
+	output := make([][]Segment, len(texts))
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(texts) {
+		workers = len(texts)
+	}
+
+	// 工作线程从任务队列中取出文本序号，分词结果按序号写回
+	task := make(chan int, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range task {
+				output[index] = seg.Segment(texts[index])
+			}
+		}()
+	}
+
+	for index := range texts {
+		task <- index
+	}
+	close(task)
+	wg.Wait()
+
+	return output
 }
 
 // InternalSegment 对文本分词
@@ -254,57 +807,283 @@ func (seg *Segmenter) InternalSegment(bytes []byte, searchMode bool) []Segment {
 }
 
 func (seg *Segmenter) internalSegment(bytes []byte, searchMode bool) []Segment {
+	segments := seg.appendSegments(nil, bytes, searchMode)
+	if segments == nil {
+		return []Segment{}
+	}
+	return segments
+}
+
+// 同internalSegment，但使用调用方取得的停用词集合，供需要再次处理停用词的全分词使用
+func (seg *Segmenter) segmentWithStopWords(bytes []byte, stopWords wordSet) []Segment {
+	segments := seg.appendAllSegments(nil, bytes, false, true)
+	segments = seg.filterStopWords(segments, stopWords)
+	if seg.reverseOutput {
+		reverseSegments(segments)
+	}
+	if segments == nil {
+		return []Segment{}
+	}
+	return segments
+}
+
+// 对文本分词，并把分词结果追加到dst之后
+func (seg *Segmenter) appendSegments(dst []Segment, bytes []byte, searchMode bool) []Segment {
+	start := len(dst)
+	dst = seg.appendAllSegments(dst, bytes, searchMode, false)
+	if seg.reverseOutput {
+		reverseSegments(dst[start:])
+	}
+	return dst
+}
+
+// 文本中pos处是否为空白字符
+func isSpaceAt(text []byte, pos int) bool {
+	if pos >= len(text) {
+		return false
+	}
+	r, _ := utf8.DecodeRune(text[pos:])
+	return unicode.IsSpace(r)
+}
+
+// 原地颠倒分词的顺序
+func reverseSegments(segs []Segment) {
+	for i, j := 0, len(segs)-1; i < j; i, j = i+1, j-1 {
+		segs[i], segs[j] = segs[j], segs[i]
+	}
+}
+
+// 对文本分词，并把分词结果追加到dst之后，keepStop为true时不处理停用词
+func (seg *Segmenter) appendAllSegments(dst []Segment, bytes []byte, searchMode, keepStop bool) []Segment {
 	// 处理特殊情况
+	if seg.inputTooLong(bytes) {
+		return dst
+	}
+	seg.stats.add(bytes)
+	return seg.appendUncountedSegments(dst, bytes, searchMode, keepStop, 0)
+}
+
+// 同appendAllSegments，但不检查文本长度，也不计入分词统计。maxMatchLength大于零时
+// 忽略长于该字元数的词典分词，见SegmentMaxLen
+func (seg *Segmenter) appendUncountedSegments(dst []Segment, bytes []byte, searchMode, keepStop bool,
+	maxMatchLength int) []Segment {
 	if len(bytes) == 0 {
-		return []Segment{}
+		return dst
 	}
 
-	// 划分字元
-	text := splitTextToWords(bytes)
+	// 去掉首尾的空白字符，分词的起止位置仍然相对于原文本
+	input := bytes
+	lead := 0
+	if seg.trimSpace {
+		var end int
+		lead, end = trimSpace(bytes)
+		bytes = bytes[lead:end]
+	}
 
-	return seg.segmentWords(text, searchMode)
+	sc := getScratch()
+	defer putScratch(sc)
+	if sc.boosts = seg.loadBoosts(); len(sc.boosts) > 0 {
+		sc.now = time.Now()
+	}
+	sc.groups = seg.loadEnabledGroups()
+	sc.maxMatchLength = maxMatchLength
+
+	// 纯ASCII文本不需要规范化
+	text := bytes
+	var offsets *offsetMap
+	if !isASCII(text) {
+		text, offsets = seg.normalizeWithOffsets(text)
+	}
+
+	start := len(dst)
+	forbidden := seg.loadForbiddenWords()
+	if len(seg.patterns) == 0 {
+		dst = seg.appendTextSegments(dst, text, lead, searchMode, forbidden, sc)
+	} else {
+		dst = seg.appendPatternSegments(dst, text, lead, searchMode, forbidden, sc)
+	}
+
+	// 规范化改变了文本时，把起止位置换回原文本中的位置
+	if offsets != nil {
+		for i := start; i < len(dst); i++ {
+			dst[i].start = lead + offsets.start(dst[i].start-lead)
+			dst[i].end = lead + offsets.end(dst[i].end-lead)
+		}
+	}
+	for i := start; i < len(dst); i++ {
+		dst[i].spaceAfter = isSpaceAt(input, dst[i].end)
+	}
+	if seg.glueChars != "" {
+		dst = dst[:start+len(seg.glueSegments(dst[start:]))]
+	}
+	if seg.unknownPos != nil {
+		EachWithContext(dst[start:], seg.inferUnknownPos)
+	}
+	if seg.normalizeNumbers {
+		dst = dst[:start+len(seg.normalizeNumberSegments(dst[start:]))]
+	}
+	if !keepStop {
+		dst = dst[:start+len(seg.filterStopWords(dst[start:], seg.loadStopWords()))]
+	}
+	return dst
+}
+
+// 对已规范化的文本分词并把分词追加到dst之后，offset为文本在原文中的起始字节位置
+func (seg *Segmenter) appendTextSegments(dst []Segment, text []byte, offset int, searchMode bool,
+	forbidden wordSet, sc *scratch) []Segment {
+	// 划分字元，纯ASCII文本可以用更简单的方法划分
+	if isASCII(text) {
+		sc.text, sc.offsets = splitASCIIWords(text, seg.split, sc.text, sc.offsets)
+	} else {
+		sc.text, sc.offsets = splitWords(text, seg.split, true, sc.text, sc.offsets)
+	}
+	if offset > 0 {
+		for i := range sc.offsets {
+			sc.offsets[i] += offset
+		}
+	}
+	if seg.splitChars != "" {
+		return seg.segmentWordsAtSplits(dst, sc.text, sc.offsets, searchMode, forbidden, sc)
+	}
+	return seg.segmentWords(dst, sc.text, sc.offsets, searchMode, forbidden, sc)
 }
 
-func (seg *Segmenter) segmentWords(text []Text, searchMode bool) []Segment {
+// 对字元数组分词并把分词追加到dst之后，offsets为每个字元在原文中的起始字节位置，
+// 可以为nil。sc为动态规划使用的临时空间，可以为nil
+func (seg *Segmenter) segmentWords(dst []Segment, text []Text, offsets []int, searchMode bool,
+	forbidden wordSet, sc *scratch) []Segment {
 	// 搜索模式下该分词已无继续划分可能的情况
 	if searchMode && len(text) == 1 {
-		return []Segment{}
+		return dst
+	}
+
+	jumpers := seg.viterbi(text, searchMode, forbidden, nil, sc)
+	return makeSegments(dst, text, offsets, jumpers)
+}
+
+// 用动态规划求解最短路径，返回每个字元处的跳转信息，forbidden中的分词不参与计算
+//
+// trace不为nil时，每个参与计算的候选分词都会调用一次trace，参数为候选分词开始处的
+// 字元序号、候选分词本身以及经由该分词的路径值。返回值使用sc的内存，sc为nil时
+// 重新分配。
+func (seg *Segmenter) viterbi(text []Text, searchMode bool, forbidden wordSet,
+	trace func(current int, token *Token, distance float32), sc *scratch) []jumper {
+	if sc == nil {
+		sc = &scratch{}
 	}
 
 	// jumpers定义了每个字元处的向前跳转信息，包括这个跳转对应的分词，
 	// 以及从文本段开始到该字元的最短路径值
-	jumpers := make([]jumper, len(text))
+	jumpers := sc.jumpers[:0]
+	for i := 0; i < len(text); i++ {
+		jumpers = append(jumpers, jumper{})
+	}
+	sc.jumpers = jumpers
 
-	tokens := make([]*Token, seg.dict.maxTokenLength)
+	if cap(sc.tokens) < seg.dict.maxTokenLength {
+		sc.tokens = make([]*Token, seg.dict.maxTokenLength)
+	}
+	tokens := sc.tokens[:seg.dict.maxTokenLength]
+	maxLength := seg.dict.maxTokenLength
+	if sc.maxMatchLength > 0 && sc.maxMatchLength < maxLength {
+		maxLength = sc.maxMatchLength
+	}
+	unknownDistance := float32(defaultUnknownDistance)
+	if seg.hasUnknownDistance {
+		unknownDistance = seg.unknownDistance
+	}
+	unknownIntDistance := quantizeDistance(unknownDistance)
+	var pseudoTokens []Token
+	var pseudoTexts []Text
+	var top []int
+	if seg.maxCandidates > 0 {
+		if cap(sc.top) < seg.maxCandidates {
+			sc.top = make([]int, seg.maxCandidates)
+		}
+		top = sc.top[:seg.maxCandidates]
+	}
 	for current := 0; current < len(text); current++ {
 		// 找到前一个字元处的最短路径，以便计算后续路径值
 		var baseDistance float32
+		var baseIntDistance int64
 		if current == 0 {
-			// 当本字元在文本首部时，基础距离应该是零
+			// 当本字元在文本首部时，基础距离应该是零。路径值只由各分词自身的词频决定
+			// （一元模型），句首、句尾的虚拟分词对所有路径的贡献相同，因此不需要
+			// <BOS>、<EOS>分词；改为按相邻分词计算路径值时需要在这里和返回前补上
 			baseDistance = 0
 		} else {
 			baseDistance = jumpers[current-1].minDistance
+			baseIntDistance = jumpers[current-1].minIntDistance
 		}
 
 		// 寻找所有以当前字元开头的分词
-		numTokens := seg.dict.lookupTokens(
-			text[current:minInt(current+seg.dict.maxTokenLength, len(text))], tokens)
+		numTokens := seg.dict.lookupTokens(text[current:minInt(current+maxLength, len(text))], tokens)
+		if len(forbidden) > 0 {
+			numTokens = removeForbiddenTokens(tokens[:numTokens], forbidden)
+		}
+		if sc.groups != nil {
+			numTokens = removeDisabledTokens(tokens[:numTokens], sc.groups)
+		}
+		if seg.maxCandidates > 0 && numTokens > seg.maxCandidates {
+			numTokens = keepFrequentTokens(tokens[:numTokens], seg.maxCandidates, top)
+		}
 
 		// 对所有可能的分词，更新分词结束字元处的跳转信息
 		for iToken := 0; iToken < numTokens; iToken++ {
 			location := current + len(tokens[iToken].text) - 1
 			if !searchMode || current != 0 || location != len(text)-1 {
-				updateJumper(&jumpers[location], baseDistance, tokens[iToken])
+				// 临时提升的分词相当于从更小的基础距离出发
+				base, intBase := baseDistance, baseIntDistance
+				if len(sc.boosts) > 0 {
+					token := tokens[iToken]
+					if boost := sc.boosts.boost(token, sc.now, seg.split.preserveCase); boost > 0 {
+						distance := token.distance - boost
+						if distance < minBoostedDistance {
+							distance = minBoostedDistance
+						}
+						base -= token.distance - distance
+						intBase -= int64(token.intDistance) - int64(quantizeDistance(distance))
+					}
+				}
+				if seg.integerDistance {
+					updateIntJumper(&jumpers[location], intBase, tokens[iToken])
+				} else {
+					updateJumper(&jumpers[location], base, tokens[iToken])
+				}
+				if trace != nil {
+					trace(current, tokens[iToken], base+tokens[iToken].distance)
+				}
 			}
 		}
 
-		// 当前字元没有对应分词时补加一个伪分词
+		// 当前字元没有对应分词时补加一个伪分词，伪分词按块分配以减少内存分配次数
 		if numTokens == 0 || len(tokens[0].text) > 1 {
-			updateJumper(&jumpers[current], baseDistance,
-				&Token{text: []Text{text[current]}, frequency: 1, distance: 32, pos: "x"})
+			if len(pseudoTokens) == 0 {
+				pseudoTokens = make([]Token, minInt(len(text)-current, pseudoTokenBlock))
+				pseudoTexts = make([]Text, len(pseudoTokens))
+			}
+			pseudoTexts[0] = text[current]
+			token := &pseudoTokens[0]
+			*token = Token{text: pseudoTexts[:1:1], frequency: 1, weight: 1, distance: unknownDistance,
+				intDistance: unknownIntDistance, pos: "x"}
+			pseudoTokens, pseudoTexts = pseudoTokens[1:], pseudoTexts[1:]
+			if seg.integerDistance {
+				updateIntJumper(&jumpers[current], baseIntDistance, token)
+			} else {
+				updateJumper(&jumpers[current], baseDistance, token)
+			}
+			if trace != nil {
+				trace(current, token, baseDistance+token.distance)
+			}
 		}
 	}
 
+	return jumpers
+}
+
+// 根据跳转信息从后向前得到最短路径上的分词，追加到dst之后
+func makeSegments(dst []Segment, text []Text, offsets []int, jumpers []jumper) []Segment {
+
 	// 从后向前扫描第一遍得到需要添加的分词数目
 	numSeg := 0
 	for index := len(text) - 1; index >= 0; {
@@ -314,7 +1093,11 @@ func (seg *Segmenter) segmentWords(text []Text, searchMode bool) []Segment {
 	}
 
 	// 从后向前扫描第二遍添加分词到最终结果
-	outputSegments := make([]Segment, numSeg)
+	start := len(dst)
+	for i := 0; i < numSeg; i++ {
+		dst = append(dst, Segment{})
+	}
+	outputSegments := dst[start:]
 	for index := len(text) - 1; index >= 0; {
 		location := index - len(jumpers[index].token.text) + 1
 		numSeg--
@@ -322,37 +1105,241 @@ func (seg *Segmenter) segmentWords(text []Text, searchMode bool) []Segment {
 		index = location - 1
 	}
 
-	// 计算各个分词的字节位置
+	// 计算各个分词的字节位置，没有字元位置时按字元连续排列计算
 	bytePosition := 0
+	wordIndex := 0
 	for iSeg := 0; iSeg < len(outputSegments); iSeg++ {
-		outputSegments[iSeg].start = bytePosition
-		bytePosition += textSliceByteLength(outputSegments[iSeg].token.text)
-		outputSegments[iSeg].end = bytePosition
+		numWords := len(outputSegments[iSeg].token.text)
+		if offsets == nil {
+			outputSegments[iSeg].start = bytePosition
+			bytePosition += textSliceByteLength(outputSegments[iSeg].token.text)
+			outputSegments[iSeg].end = bytePosition
+		} else {
+			last := wordIndex + numWords - 1
+			outputSegments[iSeg].start = offsets[wordIndex]
+			outputSegments[iSeg].end = offsets[last] + len(text[last])
+		}
+		wordIndex += numWords
+	}
+
+	return dst
+}
+
+// 计算分词的子分词，sc为动态规划使用的临时空间
+//
+// 跨过句子边界的分词（见splitTextAtSentences）按句子分别分词，子分词不会跨过句子边界，
+// 搜索模式下也不会扩展出跨句的子分词。
+func (seg *Segmenter) subSegments(text []Text, sc *scratch) []Segment {
+	pieces := splitTextAtSentences(text)
+	if len(pieces) == 1 {
+		return filterStop(seg.segmentWords(nil, text, nil, true, nil, sc), seg.stopPredicate(), nil, StopDrop)
+	}
+
+	var segments []Segment
+	offset := 0
+	for _, piece := range pieces {
+		start := len(segments)
+		segments = seg.segmentWords(segments, piece, nil, false, nil, sc)
+		for i := start; i < len(segments); i++ {
+			segments[i].start += offset
+			segments[i].end += offset
+		}
+		offset += textSliceByteLength(piece)
+	}
+	return filterStop(segments, seg.stopPredicate(), nil, StopDrop)
+}
+
+// SetMergeAlnum 设置是否把连续的字母和数字划分为一个字元，默认为false
+//
+// 默认情况下字母和数字属于不同的字元，比如"iPhone12"划分为"iphone"和"12"，打开后
+// 划分为一个字元"iphone12"，适合型号、产品编码等。词典中的分词也按此设置划分，
+// 所以需要在LoadDictionary之前设置。
+func (seg *Segmenter) SetMergeAlnum(merge bool) {
+	seg.split.mergeAlnum = merge
+}
+
+// SetSpreadSingleChars 设置全分词时是否扩展出单字的子分词，默认为true
+//
+// 设为false后FullSegment等全分词方法的结果与SpreadWithoutSingleChars相同，比如"中华"
+// 不再扩展出"中"和"华"，多字的子分词和单字本身的分词照常保留。
+func (seg *Segmenter) SetSpreadSingleChars(spread bool) {
+	seg.skipSingleChars = !spread
+}
+
+// 全分词时使用的扩展选项，expand见SpreadIf
+func (seg *Segmenter) spreadOptions(expand func(*Token) bool) spreadOptions {
+	return spreadOptions{expand: expand, maxSynonyms: -1, skipSingleChars: seg.skipSingleChars}
+}
+
+// SetWithoutSynonyms 设置载入词典时是否不处理同义词，默认为false
+//
+// 打开后词典文件中的"|"不再分隔同义词，而是分词文本的一部分，比如"C|C++ 10 n"是一个
+// 分词；载入时也不再由子分词的同义词组合出新的同义词，对不使用同义词的大词典可以明显
+// 减少载入时间和内存。需要在LoadDictionary之前设置。
+func (seg *Segmenter) SetWithoutSynonyms(without bool) {
+	seg.withoutSynonyms = without
+}
+
+// SetPreserveCase 设置是否在分词结果中保留字母原有的大小写，默认为false
+//
+// 默认情况下英文字母都被转为小写，分词文本（Token.Text）也是小写的。打开后分词文本
+// 保留词典和输入文本中原有的大小写，词典中的"iPhone"输出为"iPhone"，未登录的"HELLO"
+// 输出为"HELLO"；查找词典时仍然忽略大小写，输入"IPHONE"同样匹配"iPhone"。只有大小写
+// 不同的分词在词典中视为同一个分词，保留排在前面的。需要在LoadDictionary之前设置。
+func (seg *Segmenter) SetPreserveCase(preserve bool) {
+	seg.split.preserveCase = preserve
+}
+
+// SetTrimSpace 设置分词前是否去掉文本首尾的空白字符（unicode.IsSpace），默认为false
+//
+// 默认情况下只有半角空格被忽略，文本首尾的制表符、换行、全角空格等会成为分词。
+// 去掉首尾空白后分词的起止位置仍然是原文本中的位置，第一个分词从第一个非空白字符开始。
+func (seg *Segmenter) SetTrimSpace(trim bool) {
+	seg.trimSpace = trim
+}
+
+// SetUnknownDistance 设置未登录字元伪分词的距离，默认值为32
+//
+// 当某个字元处没有以它开头的单字分词时，分词器补加一个该字元的伪分词，其距离相当于
+// 词频为总词频的1/2^distance。距离越大越倾向于使用较长的词典分词，距离越小越倾向于
+// 把文本切成单个字元。
+func (seg *Segmenter) SetUnknownDistance(distance float32) {
+	seg.unknownDistance = distance
+	seg.hasUnknownDistance = true
+}
+
+// SetIntegerDistance 设置动态规划是否使用整数路径值，默认为false
+//
+// 打开后分词的路径值按2^20的倍数量化为整数，最短路径的计算只使用整数运算，适合浮点
+// 运算较慢的嵌入式或低功耗平台。量化误差约为百万分之一，只在两条路径的路径值几乎相等
+// 时才可能得到与浮点计算不同的结果。整数路径值在载入词典时与浮点路径值一同计算，
+// 可以随时切换，但不能与分词同时进行。
+func (seg *Segmenter) SetIntegerDistance(integer bool) {
+	seg.integerDistance = integer
+}
+
+// SetUnknownPosInference 设置根据上下文推断未登录字元词性的函数，nil表示不推断，这也是默认值
+//
+// 词典中没有的字元作为词性为"x"的伪分词输出，设置后对每个伪分词调用一次infer，
+// 参数同EachWithContext，返回非空字符串时作为该分词的词性，比如前后是称谓时把单字
+// 标为人名"nr"。分词结果按顺序处理，prev的词性可能已经被推断过。推断在处理停用词
+// 之前进行，停用词仍然出现在上下文中，SetStopFunc也能看到推断后的词性。infer会在分词
+// 过程中被调用，需要能在多个goroutine中同时使用。
+func (seg *Segmenter) SetUnknownPosInference(infer func(prev, cur, next *Segment) string) {
+	seg.unknownPos = infer
+}
+
+// 对伪分词调用推断词性的函数
+func (seg *Segmenter) inferUnknownPos(prev, cur, next *Segment) {
+	if cur.token.inDictionary || cur.token.pos != "x" || len(cur.token.text) != 1 {
+		return
+	}
+	if pos := seg.unknownPos(prev, cur, next); pos != "" {
+		cur.token.pos = pos
 	}
+}
 
-	// 过滤停止词
-	var resultSegments []Segment
-	for _, segment := range outputSegments {
-		if segment.Token().Pos() != "__STOP__" {
-			resultSegments = append(resultSegments, segment)
+// SetReverseOutput 设置是否从文本末尾向前输出分词，默认为false
+//
+// 打开后Segment、SegmentAppend等返回的分词按从后向前的顺序排列，分词的起止位置不变。
+// 顺序在分词器内部原地颠倒，不需要额外分配内存。FullSegment等全分词方法按这一顺序
+// 展开，每个分词的子分词和同义词仍然紧挨在它之前。SegmentsToText、MergeSegments等
+// 要求分词按从前向后排列，不能直接使用倒序的结果。
+func (seg *Segmenter) SetReverseOutput(reverse bool) {
+	seg.reverseOutput = reverse
+}
+
+// SetMaxInputBytes 设置输入文本的最大字节数，n小于等于零时不做限制，这也是默认值
+//
+// 超过长度的文本不做任何处理，SegmentWithError、FullSegmentWithError和
+// SegmentEncoded返回ErrInputTooLong，其余分词方法返回空的分词结果。
+func (seg *Segmenter) SetMaxInputBytes(n int) {
+	seg.maxInputBytes = n
+}
+
+// 文本是否超过SetMaxInputBytes设置的长度
+func (seg *Segmenter) inputTooLong(bytes []byte) bool {
+	return seg.maxInputBytes > 0 && len(bytes) > seg.maxInputBytes
+}
+
+// SetMaxCandidates 设置每个字元处最多考虑的候选分词数目
+//
+// 当以某字元开头的词典分词多于n个时，只保留其中词频最高的n个参与动态规划，
+// 这会略微降低分词准确度。注意该设置只限制动态规划的计算量，词典查找仍需找出全部
+// 候选分词，而查找通常占分词耗时的大部分，因此并不能明显加快分词，见基准测试
+// BenchmarkWorstCaseMaxCandidates4。n小于等于零时不做限制，这也是默认值。
+func (seg *Segmenter) SetMaxCandidates(n int) {
+	seg.maxCandidates = n
+}
+
+// 保留tokens中词频（权重）最高的n个分词，保持它们原有的先后顺序，返回保留的分词数。
+// top为长度至少为n的临时空间，用于记录当前词频最高分词的序号
+func keepFrequentTokens(tokens []*Token, n int, top []int) int {
+	// 插入排序维护词频最高的n个分词，词频相同时序号小的在前
+	numTop := 0
+	for i, token := range tokens {
+		if numTop == n && tokens[top[n-1]].weight >= token.weight {
+			continue
 		}
+		j := numTop
+		if numTop < n {
+			numTop++
+		} else {
+			j = n - 1
+		}
+		for ; j > 0 && tokens[top[j-1]].weight < token.weight; j-- {
+			top[j] = top[j-1]
+		}
+		top[j] = i
 	}
 
-	return resultSegments
+	// 按原有顺序保留排名在前n位的分词
+	last := top[numTop-1]
+	minWeight := tokens[last].weight
+	kept := 0
+	for i, token := range tokens {
+		if token.weight > minWeight ||
+			(token.weight == minWeight && i <= last) {
+			tokens[kept] = token
+			kept++
+		}
+	}
+	return kept
 }
 
 // 更新跳转信息:
 // 	1. 当该位置从未被访问过时(jumper.minDistance为零的情况)，或者
-//	2. 当该位置的当前最短路径大于新的最短路径时
+//	2. 当该位置的当前最短路径大于新的最短路径时，或者
+//	3. 路径相等，且新分词和当前分词都来自词典，新分词所在的词典文件排在前面时
 // 将当前位置的最短路径值更新为baseDistance加上新分词的概率
 func updateJumper(jumper *jumper, baseDistance float32, token *Token) {
 	newDistance := baseDistance + token.distance
-	if jumper.minDistance == 0 || jumper.minDistance > newDistance {
+	if jumper.minDistance == 0 || jumper.minDistance > newDistance ||
+		jumper.minDistance == newDistance && token.inDictionary && jumper.token.inDictionary &&
+			token.priority < jumper.token.priority {
 		jumper.minDistance = newDistance
 		jumper.token = token
 	}
 }
 
+// 同updateJumper，但使用整数路径值，见SetIntegerDistance。浮点路径值同时更新为整数路径值
+// 换算的近似值，供Lattice等输出使用
+func updateIntJumper(jumper *jumper, baseDistance int64, token *Token) {
+	newDistance := baseDistance + int64(token.intDistance)
+	if jumper.token == nil || jumper.minIntDistance > newDistance ||
+		jumper.minIntDistance == newDistance && token.inDictionary && jumper.token.inDictionary &&
+			token.priority < jumper.token.priority {
+		jumper.minIntDistance = newDistance
+		jumper.minDistance = float32(float64(newDistance) / distanceScale)
+		jumper.token = token
+	}
+}
+
+// 把分词的路径值量化为整数路径值
+func quantizeDistance(distance float32) int32 {
+	return int32(math.Round(float64(distance) * distanceScale))
+}
+
 // 取两整数较小值
 func minInt(a, b int) int {
 	if a > b {
@@ -369,9 +1356,61 @@ func maxInt(a, b int) int {
 	return b
 }
 
+// 划分字元的选项，载入词典和分词时必须使用相同的选项
+//
+// 词典中的分词文本、停用词和禁用词与待分词的文本经过完全相同的处理：先按
+// SetNormalization规范化，再按这里的选项划分字元，未保留大小写时字母转为小写。
+// 因此"IT行业"这样混合多种文字的分词在两边都划分为"it/行/业"，能与"IT行业"、
+// "it行业"等文本匹配。小写转换只针对ASCII字母，"École"与"école"被视为不同的词，
+// 需要其他文字也不区分大小写时请使用SetPreserveCase。这些设置都应在载入词典之前
+// 完成，之后修改会使两边的处理不一致。
+type splitOptions struct {
+	// 字母和数字视为同一类字符，比如"iPhone12"划分为一个字元，见SetMergeAlnum
+	mergeAlnum bool
+
+	// 保留字母的大小写，不转为小写，见SetPreserveCase
+	preserveCase bool
+}
+
+// 按分词器的设置对文本进行规范化并划分字元
+func (seg *Segmenter) splitText(text []byte) []Text {
+	words, _ := splitWords(seg.normalize(text), seg.split, false, nil, nil)
+	return words
+}
+
 // 将文本划分成字元
 func splitTextToWords(text Text) []Text {
-	output := make([]Text, 0, len(text)/3)
+	words, _ := splitWords(text, splitOptions{}, false, nil, nil)
+	return words
+}
+
+// 将文本划分成字元，同时返回每个字元在文本中的起始字节位置
+func splitTextToWordsWithOffsets(text Text) ([]Text, []int) {
+	return splitWords(text, splitOptions{}, true, nil, nil)
+}
+
+// 划分字元时丢弃的字元：半角空格、全角空格（U+3000）、零宽空格（U+200B）和
+// 字节顺序标记（U+FEFF），后几种常见于富文本编辑器输出的文本
+func isIgnorableWord(word Text) bool {
+	switch string(word) {
+	case " ", "\u3000", "\u200b", "\ufeff":
+		return true
+	}
+	return false
+}
+
+// 将文本划分成字元，output和offsets不为空时复用它们的内存
+func splitWords(text Text, options splitOptions, withOffsets bool, output []Text, offsets []int) ([]Text, []int) {
+	if cap(output) == 0 {
+		output = make([]Text, 0, len(text)/3)
+	}
+	output = output[:0]
+	if withOffsets {
+		if cap(offsets) == 0 {
+			offsets = make([]int, 0, len(text)/3)
+		}
+		offsets = offsets[:0]
+	}
 	current := 0
 	preWordType := wordAlpha
 	preWordStart := 0
@@ -384,16 +1423,22 @@ func splitTextToWords(text Text) []Text {
 			curWordType = wordAlpha
 		case size <= 2 && unicode.IsNumber(r):
 			curWordType = wordNumber
+			if options.mergeAlnum {
+				curWordType = wordAlpha
+			}
 		}
 
 		if curWordType != preWordType || curWordType == wordOther {
 			if current != 0 {
 				word := text[preWordStart:current]
-				if preWordType == wordAlpha {
+				if preWordType == wordAlpha && !options.preserveCase {
 					word = toLower(word)
 				}
-				if string(word) != " " {
+				if !isIgnorableWord(word) {
 					output = append(output, word)
+					if withOffsets {
+						offsets = append(offsets, preWordStart)
+					}
 				}
 			}
 
@@ -407,18 +1452,116 @@ func splitTextToWords(text Text) []Text {
 	// 边界情况
 	if current != 0 {
 		word := text[preWordStart:current]
-		if preWordType == wordAlpha {
+		if preWordType == wordAlpha && !options.preserveCase {
 			word = toLower(word)
 		}
-		if string(word) != " " {
+		if !isIgnorableWord(word) {
 			output = append(output, word)
+			if withOffsets {
+				offsets = append(offsets, preWordStart)
+			}
 		}
 	}
 
-	return output
+	return output, offsets
+}
+
+// 返回去掉首尾空白字符后的文本在text中的起止字节位置
+func trimSpace(text []byte) (start, end int) {
+	end = len(text)
+	for start < end {
+		r, size := utf8.DecodeRune(text[start:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		r, size := utf8.DecodeLastRune(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return
+}
+
+// 判断文本是否只包含ASCII字符
+func isASCII(text []byte) bool {
+	for _, b := range text {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// 将纯ASCII文本划分成字元，同时返回每个字元的起始字节位置，结果与splitWords相同
+//
+// 逐字节判断字符类型，不需要解码UTF-8和查询Unicode字符表；不含大写字母的单词直接
+// 引用原文本，需要转为小写的单词共用一块内存。output和offsets不为空时复用它们的内存。
+func splitASCIIWords(text Text, options splitOptions, output []Text, offsets []int) ([]Text, []int) {
+	if cap(output) == 0 {
+		output = make([]Text, 0, len(text)/3)
+	}
+	if cap(offsets) == 0 {
+		offsets = make([]int, 0, len(text)/3)
+	}
+	output, offsets = output[:0], offsets[:0]
+	var lower []byte
+	for current := 0; current < len(text); {
+		start := current
+		upper := false
+		switch b := text[current]; {
+		case options.mergeAlnum && (isASCIILetter(b) || isDigit(b)):
+			for ; current < len(text) && (isASCIILetter(text[current]) || isDigit(text[current])); current++ {
+				upper = upper || text[current] >= 'A' && text[current] <= 'Z'
+			}
+		case isASCIILetter(b):
+			for ; current < len(text) && isASCIILetter(text[current]); current++ {
+				upper = upper || text[current] <= 'Z'
+			}
+		case isDigit(b):
+			for current < len(text) && isDigit(text[current]) {
+				current++
+			}
+		case b == ' ':
+			current++
+			continue
+		default:
+			current++
+		}
+
+		word := text[start:current]
+		if upper && !options.preserveCase {
+			// 转为小写的字节总数不超过文本长度，lower不会重新分配内存
+			if lower == nil {
+				lower = make([]byte, 0, len(text))
+			}
+			from := len(lower)
+			for _, b := range word {
+				if b >= 'A' && b <= 'Z' {
+					b += 'a' - 'A'
+				}
+				lower = append(lower, b)
+			}
+			word = lower[from:len(lower):len(lower)]
+		}
+		output = append(output, word)
+		offsets = append(offsets, start)
+	}
+	return output, offsets
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
 }
 
-// 将英文词转化为小写
+// 将英文词转化为小写，只转换ASCII字母，见splitOptions
 func toLower(text []byte) []byte {
 	output := make([]byte, len(text))
 	for i, t := range text {
@@ -1,13 +1,30 @@
 package sego
 
 import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"unicode/utf8"
 )
 
 var (
 	prodSeg = Segmenter{}
 )
 
+// 确保prodSeg已载入通用词典
+func loadProdSeg() {
+	if prodSeg.dict == nil {
+		prodSeg.LoadDictionary("data/dictionary.txt")
+	}
+}
+
 func TestSplit(t *testing.T) {
 	expect(t, "中/国/有/十/三/亿/人/口/",
 		bytesToString(splitTextToWords([]byte(
@@ -21,6 +38,8 @@ func TestSplit(t *testing.T) {
 		bytesToString(splitTextToWords([]byte(
 			"中国雅虎Yahoo! China致力于，领先的公益民生门户网站。"))))
 
+	expect(t, "中/国/有/十/三/亿/人/口/", bytesToString(splitTextToWords([]byte("\ufeff中国有\u200b十三亿\u3000人口"))))
+
 	expect(t, "こ/ん/に/ち/は/", bytesToString(splitTextToWords([]byte("こんにちは"))))
 
 	expect(t, "안/녕/하/세/요/", bytesToString(splitTextToWords([]byte("안녕하세요"))))
@@ -38,6 +57,132 @@ func TestSplit(t *testing.T) {
 		bytesToString(splitTextToWords([]byte("Je56 su4904is 1enchanté000才11"))))
 }
 
+func TestSplitASCIIWords(t *testing.T) {
+	for _, text := range []string{
+		"",
+		" ",
+		"Hello World",
+		"  GET /api/v1/users?id=42  took 15ms, HTTP 200 OK.",
+		"a1B2c3 ABC123def\t\r\n~!@#$%^&*()_+",
+	} {
+		expectedWords, expectedOffsets := splitTextToWordsWithOffsets([]byte(text))
+		words, offsets := splitASCIIWords([]byte(text), splitOptions{}, nil, nil)
+		expect(t, bytesToString(expectedWords), bytesToString(words))
+		expect(t, fmt.Sprint(expectedOffsets), fmt.Sprint(offsets))
+	}
+}
+
+func TestMergeAlnum(t *testing.T) {
+	options := splitOptions{mergeAlnum: true}
+	for text, expected := range map[string]string{
+		"iPhone12": "iphone12/",
+		"3D":       "3d/",
+		"H2O":      "h2o/",
+		"A4纸 v2.0": "a4/纸/v2/./0/",
+		"2020年":    "2020/年/",
+	} {
+		words, _ := splitWords([]byte(text), options, false, nil, nil)
+		expect(t, expected, bytesToString(words))
+		if isASCII([]byte(text)) {
+			asciiWords, _ := splitASCIIWords([]byte(text), options, nil, nil)
+			expect(t, expected, bytesToString(asciiWords))
+		}
+	}
+
+	var seg Segmenter
+	seg.SetMergeAlnum(true)
+	seg.LoadDictionary("testdata/test_dict9.txt")
+	expect(t, "iphone12/nz 手机/n ", SegmentsToString(seg.Segment([]byte("iPhone12手机"))))
+	expect(t, "3d/n 打印/v ", SegmentsToString(seg.Segment([]byte("3D打印"))))
+	expect(t, "h2o/x ", SegmentsToString(seg.Segment([]byte("H2O"))))
+
+	// 默认按字母和数字划分，词典中的分词也一样
+	var defaultSeg Segmenter
+	defaultSeg.LoadDictionary("testdata/test_dict9.txt")
+	expect(t, "iphone 12/nz 手机/n ", SegmentsToString(defaultSeg.Segment([]byte("iPhone12手机"))))
+}
+
+func TestPreserveCase(t *testing.T) {
+	options := splitOptions{preserveCase: true}
+	words, _ := splitWords([]byte("Hello WORLD 中国"), options, false, nil, nil)
+	expect(t, "Hello/WORLD/中/国/", bytesToString(words))
+	asciiWords, _ := splitASCIIWords([]byte("Hello WORLD"), options, nil, nil)
+	expect(t, "Hello/WORLD/", bytesToString(asciiWords))
+
+	var seg Segmenter
+	seg.SetPreserveCase(true)
+	seg.SetMergeAlnum(true)
+	seg.LoadDictionary("testdata/test_dict9.txt")
+	seg.AddWord("iPad", 10, "nz")
+
+	// 查找时忽略大小写，输出词典中原有的大小写
+	expect(t, "iPhone12/nz 手机/n ", SegmentsToString(seg.Segment([]byte("IPHONE12手机"))))
+	expect(t, "iPad/nz 3D/n 打印/v ", SegmentsToString(seg.Segment([]byte("ipad 3D打印"))))
+	expect(t, "H2O/x ", SegmentsToString(seg.Segment([]byte("H2O"))))
+
+	// 只有大小写不同的分词视为同一个分词
+	seg.AddWord("IPAD", 20, "n")
+	expect(t, "IPAD/n ", SegmentsToString(seg.Segment([]byte("ipad"))))
+	seg.RemoveWord("Ipad")
+	expect(t, "iPad/x ", SegmentsToString(seg.Segment([]byte("iPad"))))
+
+	seg.SetStopWords([]string{"H2O"})
+	expect(t, "0", len(seg.Segment([]byte("h2o"))))
+	seg.ForbidWord("IPHONE12")
+	expect(t, "iPhone12/x ", SegmentsToString(seg.Segment([]byte("iPhone12"))))
+}
+
+func TestMixedScriptTokens(t *testing.T) {
+	const dict = "IT行业 10 n\n3D打印 10 v\nWi-Fi热点 10 n\nÉcole法语 10 n\n"
+	var base Segmenter
+	base.LoadDictionary("testdata/test_dict1.txt")
+
+	// 词典中的分词与输入文本按相同的方式划分字元和转换大小写
+	seg, _ := base.Overlay(strings.NewReader(dict))
+	for _, text := range []string{"IT行业", "it行业", "It 行业"} {
+		expect(t, "it 行业/n ", SegmentsToString(seg.Segment([]byte(text))))
+	}
+	expect(t, "3 d 打印/v wi -fi 热点/n ", SegmentsToString(seg.Segment([]byte("3d打印WI-FI热点"))))
+
+	// 只有ASCII字母转为小写
+	expect(t, "École 法语/n ", SegmentsToString(seg.Segment([]byte("École法语"))))
+	expect(t, "école/x 法/x 语/x ", SegmentsToString(seg.Segment([]byte("école法语"))))
+
+	base.SetMergeAlnum(true)
+	merged, _ := base.Overlay(strings.NewReader(dict))
+	expect(t, "it 行业/n 3d 打印/v ", SegmentsToString(merged.Segment([]byte("IT行业3D打印"))))
+
+	// 保留大小写时查找词典忽略所有文字的大小写
+	var preserveBase Segmenter
+	preserveBase.SetPreserveCase(true)
+	preserveBase.LoadDictionary("testdata/test_dict1.txt")
+	preserve, _ := preserveBase.Overlay(strings.NewReader(dict))
+	expect(t, "IT 行业/n École 法语/n ", SegmentsToString(preserve.Segment([]byte("it行业école法语"))))
+
+	// 全角字母规范化后同样匹配，停用词也一样规范化
+	var nfkcBase Segmenter
+	nfkcBase.SetNormalization(NormNFKC)
+	nfkcBase.LoadDictionary("testdata/test_dict1.txt")
+	nfkc, _ := nfkcBase.Overlay(strings.NewReader(dict))
+	expect(t, "it 行业/n ", SegmentsToString(nfkc.Segment([]byte("ＩＴ行业"))))
+	nfkc.SetStopWords([]string{"ＩＴ行业"})
+	expect(t, "0", len(nfkc.Segment([]byte("IT行业"))))
+}
+
+func TestSegmentIgnorableChars(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 字节顺序标记不会成为分词，之后分词的位置仍对应原文
+	segments := seg.Segment([]byte("\ufeff中国有十三亿人口"))
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(segments))
+	expect(t, "3", segments[0].Start())
+
+	// 零宽空格和全角空格与半角空格一样被丢弃
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ",
+		SegmentsToString(seg.Segment([]byte("中国\u200b有十三亿\u3000人口"))))
+}
+
 func TestSegment(t *testing.T) {
 	var seg Segmenter
 	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
@@ -55,6 +200,384 @@ func TestSegment(t *testing.T) {
 	expect(t, "24", segments[3].end)
 }
 
+func TestMaxTokenLengthBoundary(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	expect(t, "3", seg.dict.MaxTokenLength())
+
+	// 长度恰好为maxTokenLength的分词位于文本开头、结尾以及占满整个文本
+	expect(t, "十三亿/ 中国/ ", SegmentsToString(seg.Segment([]byte("十三亿中国"))))
+	expect(t, "中国/ 十三亿/ ", SegmentsToString(seg.Segment([]byte("中国十三亿"))))
+	expect(t, "十三亿/ ", SegmentsToString(seg.Segment([]byte("十三亿"))))
+
+	// AddWord增大maxTokenLength之后
+	seg.AddWord("中国有十三亿人口", 2, "l")
+	expect(t, "8", seg.dict.MaxTokenLength())
+	expect(t, "中国有十三亿人口/l 人口/p12 ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口人口"))))
+	expect(t, "人口/p12 中国有十三亿人口/l ", SegmentsToString(seg.Segment([]byte("人口中国有十三亿人口"))))
+	expect(t, "中国有十三亿人口/l ", SegmentsToString(seg.Segment([]byte("中国有十三亿人口"))))
+
+	// 叠加的词典比底层词典的分词更长时
+	clone := seg.Clone()
+	clone.AddWord("人口中国有十三亿人口", 2, "l")
+	expect(t, "10", clone.dict.MaxTokenLength())
+	expect(t, "人口中国有十三亿人口/l ", SegmentsToString(clone.Segment([]byte("人口中国有十三亿人口"))))
+	expect(t, "中国/ 人口中国有十三亿人口/l ", SegmentsToString(clone.Segment([]byte("中国人口中国有十三亿人口"))))
+	expect(t, "人口中国有十三亿人口/l 中国/ ", SegmentsToString(clone.Segment([]byte("人口中国有十三亿人口中国"))))
+}
+
+func TestIntegerDistance(t *testing.T) {
+	loadProdSeg()
+	integer := prodSeg.Clone()
+	integer.SetIntegerDistance(true)
+
+	file, err := os.Open("testdata/bailuyuan.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	lines := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		expect(t, SegmentsToString(prodSeg.Segment(line)), SegmentsToString(integer.Segment(line)))
+		expect(t, SegmentsToString(prodSeg.FullSegment(line)), SegmentsToString(integer.FullSegment(line)))
+		lines++
+	}
+	expect(t, "true", lines > 0)
+
+	// 未登录字元的伪分词同样使用整数路径值
+	var small Segmenter
+	small.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	small.SetUnknownDistance(0.5)
+	text := []byte("中国有十三亿人口甲乙")
+	expected := SegmentsToString(small.Segment(text))
+	expect(t, "中国/ 有/p3 十/x 三/ 亿/p5 人口/p12 甲/x 乙/x ", expected)
+	small.SetIntegerDistance(true)
+	expect(t, expected, SegmentsToString(small.Segment(text)))
+}
+
+func TestSetUnknownPosInference(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddWord("先生", 100, "n")
+	seg.SetStopWords([]string{"有"})
+
+	// 称谓前的未登录字元标为人名
+	seg.SetUnknownPosInference(func(prev, cur, next *Segment) string {
+		if next != nil && next.Token().Text() == "先生" {
+			return "nr"
+		}
+		return ""
+	})
+	expect(t, "中国/ 王/nr 先生/n 人口/p12 甲/x ", SegmentsToString(seg.Segment([]byte("中国有王先生人口甲"))))
+	expect(t, "张/x 三/ 王/x 五/nr 先生/n ", SegmentsToString(seg.Segment([]byte("张三王五先生"))))
+
+	// 推断在停用词之前进行
+	seg.SetStopFunc(func(token *Token) bool { return token.Pos() == "nr" })
+	expect(t, "中国/ 先生/n 人口/p12 甲/x ", SegmentsToString(seg.Segment([]byte("中国有王先生人口甲"))))
+
+	seg.SetUnknownPosInference(nil)
+	expect(t, "中国/ 王/x 先生/n ", SegmentsToString(seg.Segment([]byte("中国有王先生"))))
+}
+
+func TestSetReverseOutput(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetReverseOutput(true)
+	text := []byte("中国有十三亿人口")
+
+	segments := seg.Segment(text)
+	expect(t, "人口/p12 十三亿/ 有/p3 中国/ ", SegmentsToString(segments))
+	expect(t, "18 24", fmt.Sprint(segments[0].Start(), segments[0].End()))
+
+	// 追加在dst之后的部分单独颠倒
+	segments = seg.SegmentAppend(segments[:1], []byte("中国有"))
+	expect(t, "人口/p12 有/p3 中国/ ", SegmentsToString(segments))
+
+	// 全分词按倒序展开，子分词仍在原分词之前
+	expect(t, "中/p1 国/p2 中国/ 有/p3 人/p6 口/p7 人口/p12 ",
+		SegmentsToString(seg.FullSegment([]byte("人口有中国"))))
+
+	// 不影响Tags等按字符位置输出的方法
+	expect(t, "BESBMEBE", string(seg.Tags(text)))
+}
+
+func TestLoadDictionaryOverride(t *testing.T) {
+	// 两个词典中都有"中"，test_dict1.txt中为"中 64 p1"，test_dict12.txt中为"中 8 x"
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict12.txt")
+	expect(t, "7", seg.dict.NumTokens())
+	expect(t, fmt.Sprint(64*7), seg.dict.TotalFrequency())
+	token := seg.Segment([]byte("中"))[0].Token()
+	expect(t, "64", token.Frequency())
+	expect(t, "p1", token.Pos())
+	expect(t, "testdata/test_dict1.txt", token.Source())
+
+	// 交换顺序后test_dict12.txt优先
+	var reversed Segmenter
+	reversed.LoadDictionary("testdata/test_dict12.txt,testdata/test_dict1.txt")
+	expect(t, "7", reversed.dict.NumTokens())
+	expect(t, fmt.Sprint(64*6+8), reversed.dict.TotalFrequency())
+	token = reversed.Segment([]byte("中"))[0].Token()
+	expect(t, "8", token.Frequency())
+	expect(t, "x", token.Pos())
+	expect(t, "testdata/test_dict12.txt", token.Source())
+}
+
+func TestIsFrequencyText(t *testing.T) {
+	for text, expected := range map[string]bool{
+		"0": true, "16": true, "0.0031": true, "12.5": true,
+		"": false, ".5": false, "5.": false, "1.2.3": false, "-1": false, "1e3": false, "n": false, "１２": false,
+	} {
+		if isFrequencyText(text) != expected {
+			t.Errorf("isFrequencyText(%q) 期待值=%v", text, expected)
+		}
+	}
+}
+
+func TestLoadDictionaryWithErrors(t *testing.T) {
+	var seg Segmenter
+	parseErrors, err := seg.LoadDictionaryWithErrors("testdata/test_dict_bad.txt")
+	expect(t, "<nil>", err)
+
+	var reports []string
+	for _, e := range parseErrors {
+		reports = append(reports, e.Error())
+	}
+	expect(t, strings.Join([]string{
+		"testdata/test_dict_bad.txt:2: 缺少词频",
+		"testdata/test_dict_bad.txt:3: 无效的词频 abc",
+		"testdata/test_dict_bad.txt:6: 无效的权重 0.0",
+		"testdata/test_dict_bad.txt:7: 缺少词",
+		"testdata/test_dict_bad.txt:8: 缺少词频",
+	}, "\n"), strings.Join(reports, "\n"))
+
+	// 格式正确的行照常载入
+	expect(t, "3", seg.Dictionary().NumTokens())
+	expect(t, "中国/ 有/p3 亿/p5 ", SegmentsToString(seg.Segment([]byte("中国有亿"))))
+
+	_, err = seg.LoadDictionaryWithErrors("testdata/not_exist.txt")
+	expect(t, "true", err != nil)
+
+	// 无法打开的文件之前读入的格式错误照常返回
+	parseErrors, err = seg.LoadDictionaryWithErrors("testdata/test_dict_bad.txt,testdata/not_exist.txt")
+	expect(t, "true", err != nil)
+	expect(t, "5", len(parseErrors))
+}
+
+func TestLoadDictionaryWithSchema(t *testing.T) {
+	// test_dict10.txt与test_dict1.txt、test_dict2.txt内容相同，但词性在频率之前
+	var seg Segmenter
+	parseErrors, err := seg.LoadDictionaryWithSchema("testdata/test_dict10.txt", DictTextPosFrequency)
+	expect(t, "<nil>", err)
+	expect(t, "1", len(parseErrors))
+	expect(t, "testdata/test_dict10.txt:14: 无效的词频 n", parseErrors[0].Error())
+
+	var expected Segmenter
+	expected.LoadDictionaryWithSchema("testdata/test_dict1.txt,testdata/test_dict2.txt", DictTextFrequencyPos)
+	expect(t, fmt.Sprint(expected.Dictionary().NumTokens()+1), seg.Dictionary().NumTokens())
+	for _, text := range []string{"中国有十三亿人口", "国有人口", "中国人口十三"} {
+		expect(t, SegmentsToString(expected.Segment([]byte(text))), SegmentsToString(seg.Segment([]byte(text))))
+	}
+	expect(t, "hello world/p1 ", SegmentsToString(seg.Segment([]byte("hello world"))))
+}
+
+func TestWithoutSynonyms(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict11.txt")
+	segments := seg.Segment([]byte("中国C|C++"))
+	expect(t, "中国/ c/x |/x c/x +/x +/x ", SegmentsToString(segments))
+	expect(t, "中邦", segments[0].Token().SynonymsText())
+
+	// "|"是分词文本的一部分，不再组合出同义词
+	var without Segmenter
+	without.SetWithoutSynonyms(true)
+	without.LoadDictionary("testdata/test_dict11.txt")
+	segments = without.Segment([]byte("中国C|C++"))
+	expect(t, "中国/ c |c ++/n ", SegmentsToString(segments))
+	expect(t, "C|C++", "中国C|C++"[segments[1].Start():segments[1].End()])
+	expect(t, "0", len(segments[0].Token().Synonyms()))
+	expect(t, "中/ 国/x 中国/ c/x |/x c/x +/x +/x c |c ++/n ", SegmentsToString(without.FullSegment([]byte("中国C|C++"))))
+}
+
+func TestQuotedDictionaryText(t *testing.T) {
+	var seg Segmenter
+	parseErrors, _ := seg.LoadDictionaryWithErrors("testdata/test_dict7.txt")
+	expect(t, "1", len(parseErrors))
+	expect(t, "testdata/test_dict7.txt:4: 引号不匹配", parseErrors[0].Error())
+	expect(t, "3", seg.Dictionary().NumTokens())
+
+	text := []byte("web 2.0")
+	segments := seg.Segment(text)
+	expect(t, "1", len(segments))
+	expect(t, "web 2.0", string(text[segments[0].Start():segments[0].End()]))
+	expect(t, "n", segments[0].Token().Pos())
+	expect(t, "top 10/ ", SegmentsToString(seg.Segment([]byte("top 10"))))
+	expect(t, "n v/x ", SegmentsToString(seg.Segment([]byte("n v"))))
+}
+
+func TestSegmentAppend(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetStopWords([]string{"有"})
+
+	var buf []Segment
+	for _, text := range []string{"中国有十三亿人口", "人口", "", "Hello 中国"} {
+		buf = seg.SegmentAppend(buf[:0], []byte(text))
+		expect(t, SegmentsToString(seg.Segment([]byte(text))), SegmentsToString(buf))
+	}
+
+	// 追加到已有的分词之后
+	segments := seg.SegmentAppend(seg.Segment([]byte("中国")), []byte("有人口"))
+	expect(t, "中国/ 人口/p12 ", SegmentsToString(segments))
+	expect(t, "3", segments[1].Start())
+}
+
+func TestFullSegmentWithExpanded(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	segments, expanded := seg.FullSegmentWithExpanded([]byte("hello world"))
+	expect(t, "true", expanded)
+	expect(t, SegmentsToString(seg.FullSegment([]byte("hello world"))), SegmentsToString(segments))
+
+	segments, expanded = seg.FullSegmentWithExpanded([]byte("world world"))
+	expect(t, "false", expanded)
+	expect(t, "world/p3 world/p3 ", SegmentsToString(segments))
+}
+
+func TestPosList(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict8.txt")
+
+	segments := seg.Segment([]byte("研究生命起源"))
+	expect(t, "研究/v 生命/n 起源/n ", SegmentsToString(segments))
+	expect(t, "[v n]", segments[0].Token().PosList())
+	expect(t, "[n]", segments[1].Token().PosList())
+	expect(t, "[n]", segments[2].Token().PosList())
+
+	// 伪分词
+	segments = seg.Segment([]byte("的"))
+	expect(t, "[x]", segments[0].Token().PosList())
+}
+
+func TestSegmentBoth(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	for _, text := range []string{"hello world", "hoho world!", ""} {
+		normal, search := seg.SegmentBoth([]byte(text))
+		expect(t, SegmentsToString(seg.Segment([]byte(text))), SegmentsToString(normal))
+		expect(t, SegmentsToString(seg.FullSegment([]byte(text))), SegmentsToString(search))
+	}
+
+	normal, search := seg.SegmentBoth([]byte("hello world"))
+	expect(t, "hello world/p1 ", SegmentsToString(normal))
+	expect(t, "hi/p2 hoho/p2 hello/p2 world/p3 hi world/p1 hoho world/p1 hello world/p1 ", SegmentsToString(search))
+}
+
+func TestPriorityTieBreak(t *testing.T) {
+	// 两种划分的路径值相等时选择排在前面的词典中的分词
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_user.txt,testdata/test_general.txt")
+	expect(t, "甲乙/u 丙/u ", SegmentsToString(seg.Segment([]byte("甲乙丙"))))
+
+	seg.LoadDictionary("testdata/test_general.txt,testdata/test_user.txt")
+	expect(t, "甲/g 乙丙/g ", SegmentsToString(seg.Segment([]byte("甲乙丙"))))
+}
+
+func TestTokenSource(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_user.txt,testdata/test_general.txt,testdata/test_dict3.txt")
+	segments := seg.Segment([]byte("甲乙丙丁"))
+	expect(t, "testdata/test_user.txt", segments[0].Token().Source())
+	expect(t, "testdata/test_user.txt", segments[1].Token().Source())
+	expect(t, "", segments[2].Token().Source())
+
+	// 组合出的同义词记录原分词所在的文件
+	segments = seg.FullSegment([]byte("hello world"))
+	for _, segment := range segments {
+		expect(t, "testdata/test_dict3.txt", segment.Token().Source())
+	}
+
+	seg.AddWord("丙丁", 100, "")
+	expect(t, "", seg.Segment([]byte("丙丁"))[0].Token().Source())
+}
+
+func TestTokenAccessors(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	segments := seg.Segment([]byte("hello world中国，"))
+	token := segments[0].Token()
+	expect(t, "hello/world/", bytesToString(token.Words()))
+	expect(t, "2", token.Priority())
+	expect(t, "true", token.Distance() > 0)
+
+	// 路径值越小越常用，与词频的大小顺序相反
+	expect(t, "true", segments[1].Token().Distance() < token.Distance())
+	expect(t, "1", seg.Segment([]byte("人口"))[0].Token().Priority())
+	expect(t, "2", len(segments[1].Token().Segments()))
+
+	// 未登录字元的伪分词
+	unknown := segments[2].Token()
+	expect(t, "false", unknown.InDictionary())
+	expect(t, fmt.Sprint(float32(defaultUnknownDistance)), unknown.Distance())
+}
+
+func TestTokenRuneLen(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+	segments := seg.Segment([]byte("十三亿人口 hello world"))
+	expect(t, "3", segments[0].Token().RuneLen())
+	expect(t, "2", segments[1].Token().RuneLen())
+	expect(t, "10", segments[2].Token().RuneLen())
+}
+
+func TestSegmentMeta(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	segments := seg.Segment([]byte("中国人口"))
+	expect(t, "<nil>", segments[0].Meta())
+	segments[1].SetMeta(map[string]int{"id": 7})
+	expect(t, "map[id:7]", segments[1].Meta())
+
+	// 扩展时原分词带着附加的数据
+	spread := SegmentsSpread(segments)
+	expect(t, "人口", spread[len(spread)-1].Token().Text())
+	expect(t, "map[id:7]", spread[len(spread)-1].Meta())
+	expect(t, "<nil>", spread[len(spread)-2].Meta())
+}
+
+func TestUnknownDistance(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict2.txt")
+	expect(t, "人口/p12 ", SegmentsToString(seg.Segment([]byte("人口"))))
+
+	// 伪分词距离很小时倾向于逐字切分
+	seg.SetUnknownDistance(0)
+	expect(t, "人/x 口/x ", SegmentsToString(seg.Segment([]byte("人口"))))
+}
+
+func TestMaxCandidates(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	seg.SetMaxCandidates(1)
+	expect(t, "中/p1 国/p2 有/p3 十三/p10 亿/p5 人/p6 口/p7 ", SegmentsToString(seg.Segment(
+		[]byte("中国有十三亿人口"))))
+
+	seg.SetMaxCandidates(0)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(
+		[]byte("中国有十三亿人口"))))
+
+	tokens := []*Token{{weight: 1}, {weight: 5}, {weight: 3}, {weight: 5}, {weight: 3}}
+	expect(t, "3", keepFrequentTokens(tokens, 3, make([]int, 3)))
+	expect(t, "5 3 5", fmt.Sprint(tokens[0].weight, tokens[1].weight, tokens[2].weight))
+}
+
 func TestLargeDictionary(t *testing.T) {
 	prodSeg.LoadDictionary("data/dictionary.txt")
 	expect(t, "中国/ns 人口/n ", SegmentsToString(prodSeg.Segment(
@@ -76,6 +599,37 @@ func TestLargeDictionary(t *testing.T) {
 		[]byte("中华人民共和国中央人民政府"))))
 }
 
+func TestFullSegmentIf(t *testing.T) {
+	loadProdSeg()
+
+	// 只扩展机构名
+	expect(t, "中华人民共和国/ns 中央/n 人民/n 政府/n 人民政府/nt 中央人民政府/nt 中华人民共和国中央人民政府/nt ",
+		SegmentsToString(prodSeg.FullSegmentIf([]byte("中华人民共和国中央人民政府"), func(t *Token) bool {
+			return t.Pos() == "nt"
+		})))
+
+	expect(t, SegmentsToString(prodSeg.FullSegment([]byte("中华人民共和国"))),
+		SegmentsToString(prodSeg.FullSegmentIf([]byte("中华人民共和国"), nil)))
+}
+
+func TestFloatWeights(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict6.txt")
+	expect(t, "6", seg.dict.NumTokens())
+	expect(t, "1.4031", fmt.Sprintf("%.4f", seg.dict.TotalWeight()))
+
+	// 人口的权重远小于人和口，因此被拆开
+	segments := seg.Segment([]byte("中国人口"))
+	expect(t, "中国/ns 人/ 口/ ", SegmentsToString(segments))
+	expect(t, "0.5", segments[0].Token().Weight())
+	expect(t, "0", segments[0].Token().Frequency())
+	expect(t, fmt.Sprint(float32(math.Log2(1.4031))-float32(math.Log2(0.5))), segments[0].Token().distance)
+
+	// 整数词频保持不变
+	seg.LoadDictionary("testdata/test_dict1.txt")
+	expect(t, "64", seg.Segment([]byte("中"))[0].Token().Weight())
+}
+
 func TestPhraseAndSynonyms(t *testing.T) {
 	var seg Segmenter
 	seg.LoadDictionary("testdata/test_dict3.txt")
@@ -103,6 +657,159 @@ func TestPhraseAndSynonyms(t *testing.T) {
 	expect(t, "hi/p2 hoho/p2 hello/p2 hi/p2 hoho/p2 hello/p2 world/p3 hi world/p1 hoho world/p1 hello world/p1 abc/x world/p3 ", SegmentsToString(segments))
 }
 
+func TestNormalize(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	canon := map[string]string{
+		"hi world": "hello world",
+		"hoho":     "hello",
+	}
+	expect(t, "hello world hello", seg.Normalize([]byte("hi world hoho"), canon))
+	expect(t, "hello world hoho world", seg.Normalize([]byte("hello world hoho World"), canon))
+	expect(t, "hello 中国", seg.Normalize([]byte("hoho中国"), canon))
+	expect(t, "", seg.Normalize([]byte(""), canon))
+}
+
+func TestOffsetsWithSpaces(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	text := []byte("Hello  World abc")
+	segments := seg.Segment(text)
+	expect(t, "hello world/p1 abc/x ", SegmentsToString(segments))
+	expect(t, "Hello  World", string(text[segments[0].Start():segments[0].End()]))
+	expect(t, "abc", string(text[segments[1].Start():segments[1].End()]))
+}
+
+func TestTrivialInputs(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 空文本和只有空格的文本没有分词，返回空slice而不是nil
+	for _, text := range []string{"", " ", "  ", "\u3000"} {
+		normal, search := seg.SegmentBoth([]byte(text))
+		for _, segments := range [][]Segment{
+			seg.Segment([]byte(text)),
+			seg.FullSegment([]byte(text)),
+			normal,
+			search,
+		} {
+			expect(t, "0", len(segments))
+			expect(t, "false", segments == nil)
+		}
+
+		// 与append一样，没有追加分词时dst保持不变
+		expect(t, "true", seg.SegmentAppend(nil, []byte(text)) == nil)
+	}
+
+	// 单个字符成为一个分词，不在词典中的字符和标点成为未登录字元的伪分词
+	for text, expected := range map[string]string{
+		"中":  "中/p1 (0,3)",
+		"十":  "十/x (0,3)",
+		"!":  "!/x (0,1)",
+		" 中": "中/p1 (1,4)",
+		"\t": "\t/x (0,1)",
+	} {
+		for _, segments := range [][]Segment{seg.Segment([]byte(text)), seg.FullSegment([]byte(text))} {
+			expect(t, "1", len(segments))
+			expect(t, expected, fmt.Sprintf("%s(%d,%d)", SegmentsToString(segments), segments[0].Start(), segments[0].End()))
+		}
+	}
+}
+
+func TestWhitespaceOffsets(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 半角空格不会成为分词
+	text := []byte("  中国  ")
+	segments := seg.Segment(text)
+	expect(t, "中国/ ", SegmentsToString(segments))
+	expect(t, "2", segments[0].Start())
+	expect(t, "8", segments[0].End())
+
+	// 全角空格同样被丢弃，其他空白字符成为未登录字元
+	text = []byte("\t 中国\u3000\n")
+	segments = seg.Segment(text)
+	expect(t, "\t/x 中国/ \n/x ", SegmentsToString(segments))
+	expect(t, "2", segments[1].Start())
+
+	// 去掉首尾空白，位置仍然相对于原文本
+	seg.SetTrimSpace(true)
+	segments = seg.Segment(text)
+	expect(t, "中国/ ", SegmentsToString(segments))
+	expect(t, "中国", string(text[segments[0].Start():segments[0].End()]))
+	expect(t, "2", segments[0].Start())
+
+	segments = seg.Segment([]byte(" \t有 人口\n"))
+	expect(t, "有/p3 人口/p12 ", SegmentsToString(segments))
+	expect(t, "2", segments[0].Start())
+	expect(t, "6", segments[1].Start())
+	expect(t, "0", len(seg.Segment([]byte(" \t\n "))))
+}
+
+func TestSegmentSpaceAfter(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	spaces := func(segments []Segment) (output string) {
+		for _, segment := range segments {
+			output += fmt.Sprintf("%s/%v ", segment.Token().Text(), segment.SpaceAfter())
+		}
+		return
+	}
+	expect(t, "new/true york/true new/false -/false york/true 中国/true 人口/false ",
+		spaces(seg.Segment([]byte("New York new-york 中国\u3000人口"))))
+
+	// 词典中包含空格的分词按分词之后的文本判断，同义词与原分词相同
+	expect(t, "hello world/true hi/false ", spaces(seg.Segment([]byte("hello  world hi"))))
+	expect(t, "hello/true hoho/true hi/true 中/false 国/false 中国/false ", spaces(seg.FullSegment([]byte("hi 中国"))))
+
+	// 去掉首尾空白时仍按原文判断
+	seg.SetTrimSpace(true)
+	expect(t, "有/true 人口/true ", spaces(seg.Segment([]byte("有 人口\n"))))
+}
+
+func TestSetMaxInputBytes(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetMaxInputBytes(12)
+
+	text := []byte("中国有十三亿人口")
+	expect(t, "0", len(seg.Segment(text)))
+	expect(t, "0", len(seg.FullSegment(text)))
+	_, err := seg.SegmentWithError(text)
+	expect(t, ErrInputTooLong.Error(), err)
+	_, err = seg.FullSegmentWithError(text)
+	expect(t, ErrInputTooLong.Error(), err)
+
+	// 刚好达到限制的文本正常分词
+	segments, err := seg.SegmentWithError([]byte("中国有人"))
+	expect(t, "<nil>", err)
+	expect(t, "中国/ 有/p3 人/p6 ", SegmentsToString(segments))
+
+	seg.SetMaxInputBytes(0)
+	segments, err = seg.SegmentWithError(text)
+	expect(t, "<nil>", err)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(segments))
+}
+
+func TestHighlight(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	targets := map[string]bool{"中国": true, "人口": true}
+	expect(t, "<mark>中国</mark>有十三亿<mark>人口</mark>",
+		seg.Highlight([]byte("中国有十三亿人口"), targets, "<mark>", "</mark>"))
+	expect(t, "&lt;b&gt;<em>人口</em> &amp; 有",
+		seg.Highlight([]byte("<b>人口 & 有"), targets, "<em>", "</em>"))
+
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	expect(t, "<b>Hello World</b>, hi",
+		seg.Highlight([]byte("Hello World, hi"), map[string]bool{"hello world": true}, "<b>", "</b>"))
+}
+
 func TestStopword(t *testing.T) {
 	var seg Segmenter
 	seg.LoadDictionary("testdata/test_dict4.txt")
@@ -111,3 +818,356 @@ func TestStopword(t *testing.T) {
 	segments := seg.Segment([]byte("hello | hello world | world"))
 	expect(t, "hello world/p1 ", SegmentsToString(segments))
 }
+
+func TestInDictionary(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	segments := seg.Segment([]byte("hi world abc x"))
+	expect(t, "hi world/p1 abc/x x/x ", SegmentsToString(segments))
+	expect(t, "true", segments[0].Token().InDictionary())
+	expect(t, "false", segments[1].Token().InDictionary())
+	expect(t, "false", segments[2].Token().InDictionary())
+
+	// 同义词扩展出的分词同样来自词典
+	for _, synonym := range segments[0].Token().Synonyms() {
+		expect(t, "true", synonym.InDictionary())
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	// "e"加组合重音符，以及分解为字母的韩文
+	decomposed := []byte("cafe\u0301 \u1112\u1161\u11ab\u1100\u116e\u11a8")
+
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict5.txt")
+	expect(t, "cafe/x \u0301/x \u1112/x \u1161/x \u11ab/x \u1100/x \u116e/x \u11a8/x ",
+		SegmentsToString(seg.Segment(decomposed)))
+
+	seg.SetNormalization(NormNFC)
+	expect(t, "café/n 한국/ns ", SegmentsToString(seg.Segment(decomposed)))
+
+	// NFKC同时将全角字母转为半角
+	seg.SetNormalization(NormNFKC)
+	expect(t, "café/n ", SegmentsToString(seg.Segment([]byte("ｃａｆｅ\u0301"))))
+
+	// 载入词典前设置时词典文本同样被规范化
+	var nfcSeg Segmenter
+	nfcSeg.SetNormalization(NormNFC)
+	nfcSeg.LoadDictionary("testdata/test_dict5.txt")
+	expect(t, "café/n 한국/ns ", SegmentsToString(nfcSeg.Segment(decomposed)))
+}
+
+func TestNormalizationOffsets(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetNormalization(NormNFKC)
+
+	// 全角"Ａ"为3字节，规范化为1字节的"A"
+	text := []byte(" ＡＢ中国，ﬁ人口")
+	segments := seg.Segment(text)
+	expect(t, "ab/x 中国/ ,/x fi/x 人口/p12 ", SegmentsToString(segments))
+	var output []string
+	for _, segment := range segments {
+		output = append(output, string(text[segment.Start():segment.End()]))
+	}
+	expect(t, "[ＡＢ 中国 ， ﬁ 人口]", output)
+
+	// 原字符规范化为多个字符时，边界对应到整个原字符上
+	segments = seg.Segment([]byte("人㍿"))
+	expect(t, "人/p6 株/x 式/x 会/x 社/x ", SegmentsToString(segments))
+	for _, segment := range segments[1:] {
+		expect(t, "3 6", fmt.Sprint(segment.Start(), segment.End()))
+	}
+}
+
+func TestSetStopWords(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	seg.SetStopWords([]string{"World", "hello world"})
+	expect(t, "hello/p2 hi world/p1 ", SegmentsToString(seg.Segment([]byte("hello world hello hi world"))))
+	expect(t, "hello/p2 hoho/p2 hi/p2 ", SegmentsToString(seg.FullSegment([]byte("hello world hi"))))
+
+	// 扩展出的同义词同样会被过滤
+	seg.SetStopWords([]string{"hoho"})
+	expect(t, "hello/p2 hi/p2 ", SegmentsToString(seg.FullSegment([]byte("hi"))))
+
+	seg.SetStopWords(nil)
+	expect(t, "hello world/p1 ", SegmentsToString(seg.Segment([]byte("hello world"))))
+}
+
+func TestSetStopWordsConcurrent(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国有十三亿人口")
+	allowed := map[string]bool{
+		"中国/ 有/p3 十三亿/ 人口/p12 ": true,
+		"中国/ 十三亿/ 人口/p12 ":      true,
+		"中国/ 有/p3 十三亿/ ":        true,
+	}
+
+	// 一个goroutine不停替换停用词，其余goroutine同时分词
+	stop := make(chan bool)
+	updated := make(chan bool)
+	go func() {
+		defer close(updated)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				seg.SetStopWords([]string{"有"})
+			} else {
+				seg.SetStopWords([]string{"人口"})
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				output := SegmentsToString(seg.Segment(text))
+				if !allowed[output] {
+					t.Errorf("意外的分词结果 \"%s\"", output)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-updated
+}
+
+func TestSetStopWordsDuringFullSegment(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 分词过程中替换停用词，扩展出的子分词仍然按开始分词时的集合处理
+	text := []byte("中国有十三亿人口")
+	expected := SegmentsToString(seg.FullSegment(text))
+	replaced := false
+	seg.SetStopFunc(func(token *Token) bool {
+		if !replaced {
+			replaced = true
+			seg.SetStopWords([]string{"中"})
+		}
+		return false
+	})
+	expect(t, expected, SegmentsToString(seg.FullSegment(text)))
+	expect(t, "true", strings.Contains(expected, "中/p1 "))
+
+	replaced = false
+	seg.SetStopWords(nil)
+	_, search := seg.SegmentBoth(text)
+	expect(t, expected, SegmentsToString(search))
+}
+
+func TestStopMark(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict4.txt")
+	seg.SetStopMode(StopMark)
+	seg.SetStopWords([]string{"hello world"})
+
+	segments := seg.Segment([]byte("hello | hello world | world"))
+	expect(t, "hello/__STOP__ |/__STOP__ hello world/p1 |/__STOP__ world/__STOP__ ", SegmentsToString(segments))
+	for _, segment := range segments {
+		expect(t, "true", segment.IsStop())
+	}
+	expect(t, "true", segments[0].Token().IsStop())
+	expect(t, "false", segments[2].Token().IsStop())
+
+	seg.SetStopMode(StopDrop)
+	expect(t, "0", len(seg.Segment([]byte("hello | hello world | world"))))
+}
+
+func TestMayHaveStopTokens(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	expect(t, "false", seg.mayHaveStopTokens())
+
+	// 叠加词典中的停用词
+	overlay, _ := seg.Overlay(strings.NewReader("人口 16 __STOP__\n"))
+	expect(t, "true", overlay.mayHaveStopTokens())
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(overlay.Segment([]byte("中国有十三亿人口"))))
+
+	// 词性为"__STOP__"的模式
+	seg.AddPattern("digits", regexp.MustCompile("[0-9]+"), "__STOP__")
+	expect(t, "true", seg.mayHaveStopTokens())
+	expect(t, "人口/p12 ", SegmentsToString(seg.Segment([]byte("人口2020"))))
+
+	var stop Segmenter
+	stop.LoadDictionary("testdata/test_dict4.txt")
+	expect(t, "true", stop.mayHaveStopTokens())
+}
+
+func TestSetStopFunc(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口")
+
+	// 删除所有单字的分词
+	seg.SetStopFunc(func(token *Token) bool {
+		return utf8.RuneCountInString(token.Text()) == 1
+	})
+	expect(t, "中国/ 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+
+	seg.SetStopMode(StopMark)
+	segments := seg.Segment(text)
+	expect(t, "true", segments[1].IsStop())
+	expect(t, "false", segments[0].IsStop())
+
+	// SetStopWords设置的停用词仍然有效
+	seg.SetStopMode(StopDrop)
+	seg.SetStopWords([]string{"人口"})
+	expect(t, "中国/ 十三亿/ ", SegmentsToString(seg.Segment(text)))
+
+	seg.SetStopWords(nil)
+	seg.SetStopFunc(nil)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text)))
+}
+
+func TestSegmentBatch(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	texts := [][]byte{
+		[]byte("中国有十三亿人口"),
+		[]byte(""),
+		[]byte("人口"),
+		[]byte("中国"),
+	}
+	for _, workers := range []int{0, 1, 3, 10} {
+		batch := seg.SegmentBatch(texts, workers)
+		expect(t, "4", len(batch))
+		for i, text := range texts {
+			expect(t, SegmentsToString(seg.Segment(text)), SegmentsToString(batch[i]))
+		}
+	}
+	expect(t, "0", len(seg.SegmentBatch(nil, 4)))
+}
+
+// 载入基准测试使用的词典和文本
+var englishLog = []byte("2020-06-01 12:00:03 INFO server started on port 8080, pid=4242; " +
+	"loading config from /etc/app/config.yaml (mode: production) and connecting to " +
+	"database at db.example.com:5432 with user admin. Request GET /api/v1/users?id=42 " +
+	"took 15ms, status 200 OK.")
+
+func BenchmarkSegmentASCII(b *testing.B) {
+	loadProdSeg()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prodSeg.Segment(englishLog)
+	}
+}
+
+// 不走纯ASCII文本的快速路径，用于和BenchmarkSegmentASCII对比
+func BenchmarkSegmentASCIIGeneralPath(b *testing.B) {
+	loadProdSeg()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		text, offsets := splitTextToWordsWithOffsets(prodSeg.normalize(englishLog))
+		prodSeg.filterStopWords(prodSeg.segmentWords(nil, text, offsets, false, nil, nil), prodSeg.loadStopWords())
+	}
+}
+
+func BenchmarkSegmentAppend(b *testing.B) {
+	loadProdSeg()
+	b.ReportAllocs()
+	b.ResetTimer()
+	var buf []Segment
+	for i := 0; i < b.N; i++ {
+		buf = prodSeg.SegmentAppend(buf[:0], []byte("中华人民共和国中央人民政府"))
+	}
+}
+
+func BenchmarkSegmentShort(b *testing.B) {
+	loadProdSeg()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prodSeg.Segment([]byte("中华人民共和国中央人民政府"))
+	}
+}
+
+func loadBenchmarkLines(b *testing.B) [][]byte {
+	loadProdSeg()
+
+	file, err := os.Open("testdata/bailuyuan.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, []byte(scanner.Text()))
+	}
+	return lines
+}
+
+func BenchmarkSegmentSequential(b *testing.B) {
+	lines := loadBenchmarkLines(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			prodSeg.Segment(line)
+		}
+	}
+}
+
+func BenchmarkSegmentBatch(b *testing.B) {
+	lines := loadBenchmarkLines(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prodSeg.SegmentBatch(lines, 0)
+	}
+}
+
+// 生成一个每个位置都有大量重叠候选分词的词典：由1到64个"啊"组成的分词
+func loadWorstCaseDictionary(b *testing.B) *Segmenter {
+	dir, err := ioutil.TempDir("", "sego")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var content strings.Builder
+	for i := 1; i <= 64; i++ {
+		fmt.Fprintf(&content, "%s %d n\n", strings.Repeat("啊", i), 1000+i)
+	}
+	file := filepath.Join(dir, "worst.txt")
+	if err := ioutil.WriteFile(file, []byte(content.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	var seg Segmenter
+	seg.LoadDictionary(file)
+	return &seg
+}
+
+func benchmarkMaxCandidates(b *testing.B, n int) {
+	seg := loadWorstCaseDictionary(b)
+	seg.SetMaxCandidates(n)
+	text := []byte(strings.Repeat("啊", 2000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		seg.Segment(text)
+	}
+}
+
+func BenchmarkWorstCaseUnlimitedCandidates(b *testing.B) {
+	benchmarkMaxCandidates(b, 0)
+}
+
+func BenchmarkWorstCaseMaxCandidates4(b *testing.B) {
+	benchmarkMaxCandidates(b, 4)
+}
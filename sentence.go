@@ -0,0 +1,83 @@
+package sego
+
+import "unicode/utf8"
+
+// 判断字符是否为句子结尾的标点或换行
+func isSentenceTerminal(r rune) bool {
+	switch r {
+	case '。', '！', '？', '!', '?', '.', '\n', '\r':
+		return true
+	}
+	return false
+}
+
+// 在句子边界处划分分词的字元，即句末标点之后出现其他字元的位置，结果是text的子切片
+//
+// 分词的字元之间已经没有空格，无法判断英文句点是否为句末，因此不在英文句点处划分。
+func splitTextAtSentences(text []Text) [][]Text {
+	var pieces [][]Text
+	start := 0
+	inTerminal := false
+	for i, word := range text {
+		r, size := utf8.DecodeRune(word)
+		terminal := size == len(word) && r != '.' && isSentenceTerminal(r)
+		if inTerminal && !terminal {
+			pieces = append(pieces, text[start:i])
+			start = i
+		}
+		inTerminal = terminal
+	}
+	return append(pieces, text[start:])
+}
+
+// SplitSentences 按中英文句末标点（。！？.!?）和换行把文本划分成句子
+//
+// 句末标点保留在所属句子的末尾，连续的句末标点（比如"？！"或者多个换行）
+// 视为同一个句子边界，句末标点之后的空格也归入该句子。英文句点只有后面紧跟
+// 空白、其他句末标点或者文本结尾时才视为句末，以免拆开"3.14"这样的数字。
+// 返回的句子是bytes的子切片。
+func SplitSentences(bytes []byte) [][]byte {
+	var sentences [][]byte
+	start := 0
+	inTerminal := false
+	for current := 0; current < len(bytes); {
+		r, size := utf8.DecodeRune(bytes[current:])
+		terminal := isSentenceTerminal(r) || inTerminal && (r == ' ' || r == '\t')
+		if r == '.' && !inTerminal && current+size < len(bytes) {
+			next, _ := utf8.DecodeRune(bytes[current+size:])
+			terminal = next == ' ' || next == '\t' || isSentenceTerminal(next)
+		}
+
+		// 句末标点之后出现的第一个普通字符开始一个新句子
+		if inTerminal && !terminal {
+			sentences = append(sentences, bytes[start:current])
+			start = current
+		}
+		inTerminal = terminal
+		current += size
+	}
+	if start < len(bytes) {
+		sentences = append(sentences, bytes[start:])
+	}
+	return sentences
+}
+
+// SegmentBySentence 先把文本划分成句子（见SplitSentences），再对每个句子分词
+//
+// 返回值的第i个元素为第i个句子的分词，分词的起止字节位置相对于整个文本。
+// 按句子分词可以限制动态规划的规模，也便于给分词标注所在的句子。
+func (seg *Segmenter) SegmentBySentence(bytes []byte) [][]Segment {
+	sentences := SplitSentences(bytes)
+	output := make([][]Segment, len(sentences))
+	offset := 0
+	for i, sentence := range sentences {
+		segments := seg.Segment(sentence)
+		for j := range segments {
+			segments[j].start += offset
+			segments[j].end += offset
+		}
+		output[i] = segments
+		offset += len(sentence)
+	}
+	return output
+}
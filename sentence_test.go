@@ -0,0 +1,68 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func sentencesToString(sentences [][]byte) string {
+	var output []string
+	for _, sentence := range sentences {
+		output = append(output, string(sentence))
+	}
+	return strings.Join(output, "|")
+}
+
+func TestSplitSentences(t *testing.T) {
+	expect(t, "中国有十三亿人口。|真的吗？！|是的", sentencesToString(SplitSentences(
+		[]byte("中国有十三亿人口。真的吗？！是的"))))
+	expect(t, "Pi is 3.14. |Really?! |Yes.", sentencesToString(SplitSentences(
+		[]byte("Pi is 3.14. Really?! Yes."))))
+	expect(t, "第一行\n\n|第二行\r\n|第三行", sentencesToString(SplitSentences(
+		[]byte("第一行\n\n第二行\r\n第三行"))))
+	expect(t, "。|开头", sentencesToString(SplitSentences([]byte("。开头"))))
+	expect(t, "0", len(SplitSentences(nil)))
+}
+
+func TestSegmentBySentence(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国有人口。人口！")
+	sentences := seg.SegmentBySentence(text)
+	expect(t, "2", len(sentences))
+	expect(t, "中国/ 有/p3 人口/p12 。/x ", SegmentsToString(sentences[0]))
+	expect(t, "人口/p12 ！/x ", SegmentsToString(sentences[1]))
+
+	// 分词位置相对于整个文本
+	last := sentences[1][0]
+	expect(t, "人口", string(text[last.Start():last.End()]))
+}
+
+func TestSubSegmentsAcrossSentences(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.AddWordDeferred("国。人", 1000, "x")
+	seg.AddWordDeferred("中国。人口", 8, "l")
+	seg.Rebuild()
+
+	// 跨过句号的分词按句子分别扩展，不会扩展出跨句的"国。人"
+	segments := seg.FullSegment([]byte("中国。人口"))
+	expect(t, "中/p1 国/p2 中国/ 。/x 人/p6 口/p7 人口/p12 中国。人口/l ", SegmentsToString(segments))
+	expect(t, "9", segments[6].Start())
+
+	// 不跨句的分词照常扩展
+	expect(t, "国/p2 。/x 人/p6 国。人/x ", SegmentsToString(seg.FullSegment([]byte("国。人"))))
+
+	for text, expected := range map[string]string{
+		"中国。人口": "中国。/人口/",
+		"中国！？人": "中国！？/人/",
+		"v2.0":  "v 2 .0/",
+	} {
+		output := ""
+		for _, piece := range splitTextAtSentences(splitTextToWords([]byte(text))) {
+			output += textSliceToString(piece) + "/"
+		}
+		expect(t, expected, output)
+	}
+}
@@ -1,19 +1,33 @@
 /*
 
-sego分词服务器同时提供了两种模式：
+sego分词服务器同时提供了以下几种模式：
 
 	"/"	分词演示网页
 	"/json"	JSON格式的RPC服务
 		输入：
-			POST或GET模式输入text参数
+			POST或GET模式输入text参数，或者以Content-Type为text/plain的POST
+			请求体直接作为text，比如curl --data-binary @file.txt，请求体的
+			最大字节数由-max_body参数设置（默认32MB），超过时返回413
+			可选的extra参数为只对本次请求生效的额外分词，每行一个，
+			格式同词典文件："分词 词频 词性"
 		输出JSON格式：
 			{
 				segments:[
-					{"text":"服务器", "pos":"n"},
-					{"text":"指令", "pos":"n"},
+					{"text":"服务器", "pos":"n", "runes":3},
+					{"text":"指令", "pos":"n", "runes":2},
 					...
 				]
 			}
+	"/json/ndjson"	按行批量分词的JSON服务
+		输入：
+			POST请求体为NDJSON格式，每行一个JSON对象：{"text":"..."}
+		输出：
+			每个输入行对应一行JSON，格式同"/json"的输出，该行格式错误时为
+			{"segments":null, "error":"..."}。请求体读完后才开始分词，
+			结果边处理边以分块传输编码发送，不会在内存中缓存整个响应
+		限制：
+			请求体整体读入内存，最大字节数由-max_body参数设置（默认32MB），
+			每行的最大字节数由-max_line参数设置（默认1MB），超过时返回413
 
 
 测试服务器见 http://sego.weiboglass.com
@@ -23,13 +37,17 @@ sego分词服务器同时提供了两种模式：
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/pickjunk/sego"
 	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"runtime"
+	"strings"
 )
 
 var (
@@ -40,39 +58,148 @@ var (
 	segmenter = sego.Segmenter{}
 )
 
+// 请求的大小限制
+var (
+	maxBody = flag.Int64("max_body", 32<<20, "text/plain和/json/ndjson请求体的最大字节数")
+	maxLine = flag.Int("max_line", 1<<20, "/json/ndjson请求中每行的最大字节数")
+)
+
 // JSONResponse struct
 type JSONResponse struct {
 	Segments []*Segment `json:"segments"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// NDJSONRequest struct
+type NDJSONRequest struct {
+	Text string `json:"text"`
 }
 
 // Segment struct
 type Segment struct {
-	Text string `json:"text"`
-	Pos  string `json:"pos"`
+	Text  string `json:"text"`
+	Pos   string `json:"pos"`
+	Runes int    `json:"runes"`
 }
 
 // JSONRPCServer func
 func JSONRPCServer(w http.ResponseWriter, req *http.Request) {
 	// 得到要分词的文本
-	text := req.URL.Query().Get("text")
-	if text == "" {
-		text = req.PostFormValue("text")
+	var text string
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "text/plain" {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, *maxBody))
+		if err != nil && int64(len(body)) >= *maxBody {
+			http.Error(w, fmt.Sprintf("请求体超过%d字节", *maxBody), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "无法读取请求", http.StatusBadRequest)
+			return
+		}
+		text = string(body)
+	} else {
+		text = req.URL.Query().Get("text")
+		if text == "" {
+			text = req.PostFormValue("text")
+		}
 	}
 
-	// 分词
-	segments := segmenter.Segment([]byte(text))
-
-	// 整理为输出格式
-	ss := []*Segment{}
-	for _, segment := range segments {
-		ss = append(ss, &Segment{Text: segment.Token().Text(), Pos: segment.Token().Pos()})
+	// 额外的分词叠加在词典之上，只对本次请求生效
+	var seg *sego.Segmenter
+	extra := req.URL.Query().Get("extra")
+	if extra == "" {
+		extra = req.PostFormValue("extra")
+	}
+	if extra != "" {
+		var parseErrors []sego.DictParseError
+		seg, parseErrors = segmenter.Overlay(strings.NewReader(extra))
+		if len(parseErrors) > 0 {
+			e := parseErrors[0]
+			http.Error(w, fmt.Sprintf("extra参数第%d行格式错误：%s", e.Line, e.Reason), http.StatusBadRequest)
+			return
+		}
+	} else {
+		// 分词器可以在多个goroutine中同时使用，所有请求共用同一个
+		seg = &segmenter
 	}
-	response, _ := json.Marshal(&JSONResponse{Segments: ss})
+
+	// 分词
+	response, _ := json.Marshal(&JSONResponse{Segments: segment(seg, text)})
 
 	w.Header().Set("Content-Type", "application/json")
 	io.WriteString(w, string(response))
 }
 
+// NDJSONServer func
+func NDJSONServer(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "请使用POST请求", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// HTTP/1.x中开始写响应后可能无法继续读取请求体，因此先读入所有行，请求体和每行的
+	// 长度都有上限，以免过大的请求耗尽内存
+	var lines [][]byte
+	read := int64(0)
+	reader := bufio.NewReaderSize(http.MaxBytesReader(w, req.Body, *maxBody), *maxLine)
+	for {
+		line, err := reader.ReadSlice('\n')
+		read += int64(len(line))
+		if err == bufio.ErrBufferFull {
+			http.Error(w, fmt.Sprintf("请求中的行超过%d字节", *maxLine), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if len(strings.TrimSpace(string(line))) > 0 {
+			lines = append(lines, append([]byte{}, line...))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil && read >= *maxBody {
+			http.Error(w, fmt.Sprintf("请求体超过%d字节", *maxBody), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("读取请求失败")
+			http.Error(w, "读取请求失败", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	for _, line := range lines {
+		var request NDJSONRequest
+		response := JSONResponse{}
+		if e := json.Unmarshal(line, &request); e != nil {
+			response.Error = e.Error()
+		} else {
+			response.Segments = segment(&segmenter, request.Text)
+		}
+		if encoder.Encode(&response) != nil {
+			// 客户端已断开
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// 分词并整理为输出格式
+func segment(seg *sego.Segmenter, text string) []*Segment {
+	ss := []*Segment{}
+	for _, segment := range seg.Segment([]byte(text)) {
+		ss = append(ss, &Segment{
+			Text:  segment.Token().Text(),
+			Pos:   segment.Token().Pos(),
+			Runes: segment.Token().RuneLen(),
+		})
+	}
+	return ss
+}
+
 func main() {
 	flag.Parse()
 
@@ -83,6 +210,7 @@ func main() {
 	segmenter.LoadDictionary(*dict)
 
 	http.HandleFunc("/json", JSONRPCServer)
+	http.HandleFunc("/json/ndjson", NDJSONServer)
 	http.Handle("/", http.FileServer(http.Dir(*staticFolder)))
 	log.Info().Msg("服务器启动")
 	http.ListenAndServe(fmt.Sprintf("%s:%d", *host, *port), nil)
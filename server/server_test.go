@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONServerLargeBody(t *testing.T) {
+	segmenter.LoadDictionary("../testdata/test_dict1.txt,../testdata/test_dict2.txt")
+	server := httptest.NewServer(http.HandlerFunc(NDJSONServer))
+	defer server.Close()
+
+	// 请求体远大于bufio的缓冲区，写响应时请求体还没有读完
+	const numLines = 2000
+	body := strings.Repeat(`{"text":"中国有十三亿人口，中国有十三亿人口"}`+"\n", numLines)
+	resp, err := http.Post(server.URL, "application/x-ndjson", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if !strings.HasPrefix(scanner.Text(), `{"segments":[{"text":"中国"`) {
+			t.Fatalf("第%d行：%s", lines+1, scanner.Text())
+		}
+		lines++
+	}
+	if lines != numLines {
+		t.Errorf("期待%d行，实际%d行", numLines, lines)
+	}
+}
+
+func TestNDJSONServerLimits(t *testing.T) {
+	segmenter.LoadDictionary("../testdata/test_dict1.txt,../testdata/test_dict2.txt")
+	server := httptest.NewServer(http.HandlerFunc(NDJSONServer))
+	defer server.Close()
+
+	oldBody, oldLine := *maxBody, *maxLine
+	defer func() { *maxBody, *maxLine = oldBody, oldLine }()
+	*maxBody, *maxLine = 1000, 100
+
+	line := `{"text":"中国有十三亿人口"}` + "\n"
+	for body, expected := range map[string]int{
+		strings.Repeat(line, 10):                             http.StatusOK,
+		strings.Repeat(line, 100):                            http.StatusRequestEntityTooLarge,
+		`{"text":"` + strings.Repeat("中国", 50) + `"}` + "\n": http.StatusRequestEntityTooLarge,
+	} {
+		resp, err := http.Post(server.URL, "application/x-ndjson", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("%d字节的请求：期待状态%d，实际%d", len(body), expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestJSONRPCServerBodyLimit(t *testing.T) {
+	segmenter.LoadDictionary("../testdata/test_dict1.txt,../testdata/test_dict2.txt")
+	server := httptest.NewServer(http.HandlerFunc(JSONRPCServer))
+	defer server.Close()
+
+	oldBody := *maxBody
+	defer func() { *maxBody = oldBody }()
+	*maxBody = 100
+
+	for body, expected := range map[string]int{
+		strings.Repeat("中国", 10): http.StatusOK,
+		strings.Repeat("中国", 20): http.StatusRequestEntityTooLarge,
+	} {
+		resp, err := http.Post(server.URL, "text/plain; charset=utf-8", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("%d字节的请求：期待状态%d，实际%d", len(body), expected, resp.StatusCode)
+		}
+	}
+}
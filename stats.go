@@ -0,0 +1,51 @@
+package sego
+
+import (
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// SegmenterStats 分词器的累计统计，见Segmenter.Stats
+type SegmenterStats struct {
+	// 分词次数
+	Calls int64
+
+	// 分词文本的总字节数
+	Bytes int64
+
+	// 分词文本的总字符数
+	Runes int64
+}
+
+// 分词器内部的统计计数，只通过原子操作访问
+type segmenterStats struct {
+	calls int64
+	bytes int64
+	runes int64
+}
+
+func (stats *segmenterStats) add(text []byte) {
+	atomic.AddInt64(&stats.calls, 1)
+	atomic.AddInt64(&stats.bytes, int64(len(text)))
+	atomic.AddInt64(&stats.runes, int64(utf8.RuneCount(text)))
+}
+
+// Stats 返回载入词典（或上次ResetStats）以来的分词统计，可以用于按字符计费
+//
+// 每对一段文本分词计为一次，比如SegmentBatch和SegmentBySentence中的每段文本、每个
+// 句子各计一次；超过SetMaxInputBytes设置的长度而未分词的文本不计入。统计使用原子
+// 操作，可以在分词的同时调用。Overlay返回的分词器单独统计。
+func (seg *Segmenter) Stats() SegmenterStats {
+	return SegmenterStats{
+		Calls: atomic.LoadInt64(&seg.stats.calls),
+		Bytes: atomic.LoadInt64(&seg.stats.bytes),
+		Runes: atomic.LoadInt64(&seg.stats.runes),
+	}
+}
+
+// ResetStats 把分词统计清零
+func (seg *Segmenter) ResetStats() {
+	atomic.StoreInt64(&seg.stats.calls, 0)
+	atomic.StoreInt64(&seg.stats.bytes, 0)
+	atomic.StoreInt64(&seg.stats.runes, 0)
+}
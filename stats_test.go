@@ -0,0 +1,43 @@
+package sego
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	expect(t, "{0 0 0}", seg.Stats())
+
+	seg.Segment([]byte("中国有十三亿人口"))
+	seg.FullSegment([]byte("hello 人口"))
+	seg.Segment(nil)
+	expect(t, "{3 36 16}", seg.Stats())
+
+	// 超过长度限制的文本不计入
+	seg.SetMaxInputBytes(8)
+	seg.Segment([]byte("中国有十三亿人口"))
+	expect(t, "{3 36 16}", seg.Stats())
+
+	seg.ResetStats()
+	expect(t, "{0 0 0}", seg.Stats())
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				seg.Segment([]byte("中国有十三亿人口"))
+			}
+		}()
+	}
+	wg.Wait()
+	expect(t, "{800 19200 6400}", seg.Stats())
+}
@@ -0,0 +1,140 @@
+package sego
+
+import "bytes"
+
+// StopMode 停用词的处理方式
+type StopMode int
+
+const (
+	// StopDrop 从分词结果中删除停用词，默认值
+	StopDrop StopMode = iota
+
+	// StopMark 在分词结果中保留停用词，并通过Segment.IsStop标记
+	StopMark
+)
+
+// 词语集合，用于停用词和禁用词，键为分词各字元拼接后的字节串
+type wordSet map[string]struct{}
+
+// 由词语列表创建集合，词语按载入词典时相同的方式规范化并划分字元，保留大小写时
+// 与查找词典一样转为小写
+func (seg *Segmenter) newWordSet(words []string) wordSet {
+	set := make(wordSet, len(words))
+	for _, word := range words {
+		key := textSliceToBytes(seg.splitText([]byte(word)))
+		if seg.split.preserveCase {
+			key = foldCase(key)
+		}
+		if len(key) > 0 {
+			set[string(key)] = struct{}{}
+		}
+	}
+	return set
+}
+
+// 检测分词是否在集合中，找不到时再按小写查找，以便在保留大小写时同样匹配（见
+// SetPreserveCase）
+func (set wordSet) containsToken(token *Token) bool {
+	if len(set) == 0 {
+		return false
+	}
+
+	var buf [64]byte
+	key := buf[:0]
+	if len(token.text) == 1 {
+		key = token.text[0]
+	} else {
+		for _, word := range token.text {
+			key = append(key, word...)
+		}
+	}
+	if _, ok := set[string(key)]; ok {
+		return true
+	}
+	if folded := foldCase(key); !bytes.Equal(folded, key) {
+		_, ok := set[string(folded)]
+		return ok
+	}
+	return false
+}
+
+// SetStopWords 设置停用词，停用词的处理方式见SetStopMode
+//
+// 每次调用都会整体替换之前的停用词集合。替换是原子的，可以在其他goroutine
+// 正在分词时调用：每次分词要么完全使用旧的集合，要么完全使用新的集合，全分词时
+// 原分词和扩展出的分词也使用同一个集合。
+//
+// 停用词与词典中的分词一样按SetNormalization的设置规范化，请在设置规范化之后调用。
+func (seg *Segmenter) SetStopWords(words []string) {
+	seg.stopWords.Store(seg.newWordSet(words))
+	seg.cache.clear()
+}
+
+// 当前的停用词集合
+func (seg *Segmenter) loadStopWords() wordSet {
+	set, _ := seg.stopWords.Load().(wordSet)
+	return set
+}
+
+// SetStopMode 设置停用词的处理方式，默认删除停用词
+//
+// 停用词包括SetStopFunc判定的分词（默认为词典中词性为"__STOP__"的分词）以及
+// SetStopWords设置的停用词。
+func (seg *Segmenter) SetStopMode(mode StopMode) {
+	seg.stopMode = mode
+}
+
+// SetStopFunc 设置判定停用词的函数，nil表示恢复默认的Token.IsStop
+//
+// 可以按词性、长度等条件判定停用词，比如删除所有单字的虚词。SetStopWords设置的
+// 停用词仍然有效，处理方式见SetStopMode。子分词中的停用词同样由该函数判定。
+// 该函数会在分词过程中被调用，需要能在多个goroutine中同时使用。
+func (seg *Segmenter) SetStopFunc(isStop func(token *Token) bool) {
+	seg.stopFunc = isStop
+}
+
+// 当前判定停用词的函数
+func (seg *Segmenter) stopPredicate() func(token *Token) bool {
+	if seg.stopFunc == nil {
+		return (*Token).IsStop
+	}
+	return seg.stopFunc
+}
+
+// 按分词器的设置处理分词结果中的停用词，stopWords为调用方取得的停用词集合
+func (seg *Segmenter) filterStopWords(segs []Segment, stopWords wordSet) []Segment {
+	if len(stopWords) == 0 && !seg.mayHaveStopTokens() {
+		// 大多数情况下没有停用词，不需要逐个检查
+		return segs
+	}
+	return filterStop(segs, seg.stopPredicate(), stopWords, seg.stopMode)
+}
+
+// 分词结果中是否可能有按词性判定的停用词：词典中有词性为"__STOP__"的分词，或者词性
+// 来自词典之外（SetStopFunc、模式和推断的词性）
+func (seg *Segmenter) mayHaveStopTokens() bool {
+	if seg.stopFunc != nil || seg.unknownPos != nil || seg.dict.hasStopTokens() {
+		return true
+	}
+	for _, p := range seg.patterns {
+		if p.pos == "__STOP__" {
+			return true
+		}
+	}
+	return false
+}
+
+// 删除或者标记分词结果中的停用词，结果直接写回segs
+func filterStop(segs []Segment, isStop func(token *Token) bool, stopWords wordSet, mode StopMode) []Segment {
+	output := segs[:0]
+	for _, segment := range segs {
+		if isStop(segment.token) || stopWords.containsToken(segment.token) {
+			if mode == StopDrop {
+				continue
+			}
+			segment.stop = true
+		}
+		output = append(output, segment)
+	}
+	return output
+}
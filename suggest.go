@@ -0,0 +1,114 @@
+package sego
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// 一个纠错候选词
+type suggestion struct {
+	text     string
+	distance int
+	weight   float64
+}
+
+// Suggest 返回词典中与word编辑距离不超过maxDistance的分词，可以用于查询纠错
+//
+// 编辑距离为按字符计算的插入、删除、替换次数。word先按分词文本的方式处理，比如英文
+// 转为小写，再与分词的Text()比较，保留大小写时（见SetPreserveCase）忽略大小写比较；
+// word本身在词典中时也会返回，距离为0。结果按编辑距离从小到大排列，距离相同时权重
+// 高的在前，最多返回limit个，limit小于等于零时不限制。
+// 每次调用都要遍历整个词典，适合对分词结果中的少数未登录词调用。
+func (seg *Segmenter) Suggest(word string, maxDistance int, limit int) []string {
+	if seg.dict == nil || maxDistance < 0 {
+		return nil
+	}
+	fold := seg.split.preserveCase
+	key := Join(seg.splitText([]byte(word)))
+	if fold {
+		key = string(foldCase([]byte(key)))
+	}
+	target := []rune(key)
+	if len(target) == 0 {
+		return nil
+	}
+
+	var suggestions []suggestion
+	seen := make(map[string]bool)
+	row := make([]int, len(target)+1)
+	for dict := seg.dict; dict != nil; dict = dict.parent {
+		// 叠加词典的分词优先于底层词典的同名分词
+		for _, token := range dict.tokens {
+			text := token.Text()
+			if seen[text] {
+				continue
+			}
+			seen[text] = true
+
+			length := utf8.RuneCountInString(text)
+			if length < len(target)-maxDistance || length > len(target)+maxDistance {
+				continue
+			}
+			compared := text
+			if fold {
+				compared = string(foldCase([]byte(text)))
+			}
+			if distance := boundedEditDistance(target, compared, maxDistance, row); distance <= maxDistance {
+				suggestions = append(suggestions, suggestion{text: text, distance: distance, weight: token.weight})
+			}
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.weight != b.weight {
+			return a.weight > b.weight
+		}
+		return a.text < b.text
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	output := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		output[i] = s.text
+	}
+	return output
+}
+
+// 计算a与b的编辑距离，确定超过bound时提前返回bound+1。row为长度至少为len(a)+1的临时空间
+func boundedEditDistance(a []rune, b string, bound int, row []int) int {
+	for i := range a {
+		row[i+1] = i + 1
+	}
+	row[0] = 0
+
+	j := 0
+	for _, r := range b {
+		j++
+		// diagonal为上一行第i列的值，row[i]更新前为上一行的值
+		diagonal := row[0]
+		row[0] = j
+		rowMin := row[0]
+		for i := range a {
+			cost := 1
+			if a[i] == r {
+				cost = 0
+			}
+			value := minInt(minInt(row[i+1]+1, row[i]+1), diagonal+cost)
+			diagonal = row[i+1]
+			row[i+1] = value
+			if value < rowMin {
+				rowMin = value
+			}
+		}
+		if rowMin > bound {
+			return bound + 1
+		}
+	}
+	return row[len(a)]
+}
@@ -0,0 +1,42 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	// 距离相同时权重高的在前
+	expect(t, "[中 中国]", seg.Suggest("中囯", 1, 0))
+	expect(t, "[十三 十三亿]", seg.Suggest("十三忆", 1, 0))
+	expect(t, "[十三]", seg.Suggest("十三忆", 1, 1))
+	expect(t, "[人口]", seg.Suggest("人口", 0, 0))
+	expect(t, "[人口 人 口 三 中 亿]", seg.Suggest("人口", 2, 6))
+
+	// 英文按小写比较
+	expect(t, "[hello]", seg.Suggest("Helo", 1, 0))
+	expect(t, "[hello world]", seg.Suggest("hello  worlds", 1, 0))
+
+	expect(t, "[]", seg.Suggest("", 1, 0))
+	expect(t, "[]", seg.Suggest("中国", -1, 0))
+}
+
+func TestSuggestWithOverlay(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	overlay, _ := seg.Overlay(strings.NewReader("中华 128 n\n中国 1 n\n"))
+
+	expect(t, "[中华 中 中国]", overlay.Suggest("中囯", 1, 0))
+	expect(t, "[中 中国]", seg.Suggest("中囯", 1, 0))
+}
+
+func Test_boundedEditDistance(t *testing.T) {
+	row := make([]int, 16)
+	expect(t, "3", boundedEditDistance([]rune("kitten"), "sitting", 5, row))
+	expect(t, "3", boundedEditDistance([]rune("kitten"), "sitting", 2, row))
+	expect(t, "0", boundedEditDistance([]rune("中国"), "中国", 0, row))
+	expect(t, "2", boundedEditDistance([]rune("中国"), "", 2, row))
+}
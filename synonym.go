@@ -0,0 +1,151 @@
+package sego
+
+// AreSynonyms 返回a和b是否互为同义词
+//
+// 同义词包括词典中同一行用"|"分隔的分词，以及载入词典时由子分词的同义词组合出的
+// 分词，比如"hello|hi"时"hello world"和"hi world"互为同义词。a和b都必须是词典中的
+// 分词，同一个词不算作自己的同义词。判断是对称的，只要一方在另一方的同义词中即可。
+func (seg *Segmenter) AreSynonyms(a, b string) bool {
+	tokenA, tokenB := seg.lookupWord(a), seg.lookupWord(b)
+	if tokenA == nil || tokenB == nil || tokenA == tokenB {
+		return false
+	}
+	return hasSynonym(tokenA, tokenB) || hasSynonym(tokenB, tokenA)
+}
+
+// token的同义词中是否有与synonym文本相同的分词
+func hasSynonym(token, synonym *Token) bool {
+	key := string(textSliceToBytes(synonym.text))
+	for _, t := range token.synonyms {
+		if t.TextEquals(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// 在词典中查找文本恰好为text的分词，没有时返回nil
+func (seg *Segmenter) lookupWord(text string) *Token {
+	words := seg.splitText([]byte(text))
+	if seg.dict == nil || len(words) == 0 || len(words) > seg.dict.maxTokenLength {
+		return nil
+	}
+	tokens := make([]*Token, len(words))
+	numTokens := seg.dict.lookupTokens(words, tokens)
+	if numTokens > 0 && len(tokens[numTokens-1].text) == len(words) {
+		return tokens[numTokens-1]
+	}
+	return nil
+}
+
+// AddSynonym 使word与synonyms互为同义词并立即重建词典，见Rebuild
+//
+// 词典中原有的同义词关系保留，并且是传递的：word原来的同义词与synonyms原来的同义词
+// 也都互为同义词，相当于把它们在词典中所在的行合并为一行。词典中没有的词以word的
+// 权重添加，word也不在词典中时权重为词频下限，没有词性。重复的词只算一次，一个词
+// 不会成为自己的同义词。在副本（见Clone）上调用时，只在共享词典中的词连同它所在的行
+// 复制到副本的词典中，保留原来的权重和词性。与AddWord一样每次调用都要重建整个词典，
+// 批量添加时请使用AddSynonymDeferred，最后调用一次Rebuild。
+func (seg *Segmenter) AddSynonym(word string, synonyms ...string) {
+	seg.AddSynonymDeferred(word, synonyms...)
+	seg.Rebuild()
+}
+
+// AddSynonymDeferred 同AddSynonym，但不重建词典，调用Rebuild后才生效
+func (seg *Segmenter) AddSynonymDeferred(word string, synonyms ...string) {
+	if seg.dict == nil {
+		seg.dict = NewDictionary()
+	}
+
+	// 找出每个词所在的组，同样文本的分词出现在多个组中时只有最先载入的生效
+	selected := make(map[int]bool)
+	first := -1
+	weight := float64(minTokenFrequency)
+	var missing [][]Text
+	var inherited []*Token
+	seen := make(map[string]bool)
+	for i, text := range append([]string{word}, synonyms...) {
+		words := seg.splitText([]byte(text))
+		key := string(foldCase(textSliceToBytes(words)))
+		if len(words) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		index, token := seg.findGroup(words)
+		if token == nil {
+			// 只在底层词典中的词连同它所在的组复制到当前词典
+			group, parentToken := seg.copyParentGroup(words)
+			if parentToken == nil {
+				missing = append(missing, words)
+				continue
+			}
+			inherited = append(inherited, group...)
+			if i == 0 {
+				weight = parentToken.weight
+			}
+			continue
+		}
+		selected[index] = true
+		if first < 0 || index < first {
+			first = index
+		}
+		if i == 0 {
+			weight = token.weight
+		}
+	}
+
+	// 合并所在的组，合并后的组放在最靠前的组的位置，没有的词添加在最后
+	var merged []*Token
+	position := -1
+	groups := make([][]*Token, 0, len(seg.dict.groups)+1)
+	for i, group := range seg.dict.groups {
+		if !selected[i] {
+			groups = append(groups, group)
+			continue
+		}
+		if i == first {
+			position = len(groups)
+			groups = append(groups, nil)
+		}
+		merged = append(merged, group...)
+	}
+	merged = append(merged, inherited...)
+	for _, words := range missing {
+		merged = append(merged, &Token{text: words, frequency: int(weight), weight: weight, inDictionary: true})
+	}
+
+	// 合并的组中有相同文本的分词时只保留第一个，以免分词成为自己的同义词
+	unique := merged[:0]
+	texts := make(map[string]bool)
+	for _, token := range merged {
+		key := textSliceToBytes(token.text)
+		if seg.split.preserveCase {
+			key = foldCase(key)
+		}
+		if !texts[string(key)] {
+			texts[string(key)] = true
+			unique = append(unique, token)
+		}
+	}
+	merged = unique
+	if position < 0 {
+		groups = append(groups, merged)
+	} else {
+		groups[position] = merged
+	}
+	seg.dict.groups = groups
+}
+
+// 在原始分词中找出第一个文本为words的分词及其所在组的序号，没有时返回nil
+func (seg *Segmenter) findGroup(words []Text) (int, *Token) {
+	matches := seg.wordMatcher(string(textSliceToBytes(words)))
+	for i, group := range seg.dict.groups {
+		for _, token := range group {
+			if matches(token) {
+				return i, token
+			}
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,88 @@
+package sego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAreSynonyms(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+
+	for _, pair := range [][2]string{
+		{"hello", "hi"}, {"hi", "hello"}, {"hi", "hoho"},
+		{"hello world", "hi world"}, {"hi world", "hello world"}, {"hi world", "hoho world"},
+	} {
+		expect(t, "true", seg.AreSynonyms(pair[0], pair[1]))
+	}
+	for _, pair := range [][2]string{
+		{"hello", "hello"}, {"hi world", "hi world"}, {"hello", "world"},
+		{"hello", "hello world"}, {"hello", "unknown"}, {"", "hi"},
+	} {
+		expect(t, "false", seg.AreSynonyms(pair[0], pair[1]))
+	}
+
+	// 同一行的同义词和组合出的同义词同时保留
+	var dict11 Segmenter
+	dict11.LoadDictionary("testdata/test_dict11.txt")
+	overlay, _ := dict11.Overlay(strings.NewReader("中国 32 ns|华夏 32 ns\n"))
+	expect(t, "true", overlay.AreSynonyms("中国", "华夏"))
+	expect(t, "true", overlay.AreSynonyms("中国", "中邦"))
+	expect(t, "true", overlay.AreSynonyms("中邦", "中国"))
+	expect(t, "false", overlay.AreSynonyms("华夏", "中邦"))
+	expect(t, "华夏 中邦", overlay.Segment([]byte("中国"))[0].Token().SynonymsText())
+}
+
+func TestAddSynonym(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	// 已在词典中的词，同义词关系是传递的
+	seg.AddSynonym("world", "hello", "world")
+	expect(t, "true", seg.AreSynonyms("world", "hi"))
+	expect(t, "true", seg.AreSynonyms("hoho", "world"))
+	expect(t, "false", seg.AreSynonyms("world", "world"))
+	expect(t, "hello hi hoho", seg.lookupWord("world").SynonymsText())
+	expect(t, "hello/p2 hoho/p2 world/p3 hi/p2 ", SegmentsToString(seg.FullSegment([]byte("hi"))))
+
+	// 不在词典中的词以word的权重添加
+	seg.AddSynonym("人口", "居民", "人口")
+	expect(t, "true", seg.AreSynonyms("人口", "居民"))
+	expect(t, "16", seg.lookupWord("居民").Frequency())
+	expect(t, "人口", seg.lookupWord("居民").SynonymsText())
+	expect(t, "居/x 民/x 人口/p12 居民/ ", SegmentsToString(seg.FullSegment([]byte("居民"))))
+
+	// 都不在词典中
+	seg.AddSynonym("甲乙", "丙丁")
+	expect(t, "true", seg.AreSynonyms("丙丁", "甲乙"))
+	expect(t, fmt.Sprint(minTokenFrequency), seg.lookupWord("甲乙").Frequency())
+}
+
+func TestAddSynonymOnClone(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	// 共享词典中的词保留原来的权重和词性，原来所在的行一并合并
+	clone := seg.Clone()
+	clone.AddSynonym("人口", "居民")
+	expect(t, "人口/p12 ", SegmentsToString(clone.Segment([]byte("人口"))))
+	expect(t, "16", clone.lookupWord("居民").Frequency())
+	clone.AddSynonym("hi", "人口")
+	expect(t, "居民 hello hi hoho", clone.lookupWord("人口").SynonymsText())
+
+	// 共享词典不受影响
+	expect(t, "false", seg.AreSynonyms("人口", "居民"))
+	expect(t, "hi hoho", seg.lookupWord("hello").SynonymsText())
+}
+
+func TestAddSynonymDuplicateTexts(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt")
+	overlay, _ := seg.Overlay(strings.NewReader("甲 10|乙 10\n乙 10|丙 10\n"))
+
+	// 两行中都有"乙"，合并后只保留一个，"乙"不会成为自己的同义词
+	overlay.AddSynonym("甲", "丙")
+	expect(t, "甲 丙", overlay.lookupWord("乙").SynonymsText())
+	expect(t, "甲/ 丙/ 乙/ ", SegmentsToString(overlay.FullSegment([]byte("乙"))))
+}
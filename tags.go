@@ -0,0 +1,47 @@
+package sego
+
+import "unicode/utf8"
+
+// 字符在分词中的位置标注，见Tags
+const (
+	TagBegin  = 'B' // 多字分词的第一个字符
+	TagMiddle = 'M' // 多字分词中间的字符
+	TagEnd    = 'E' // 多字分词的最后一个字符
+	TagSingle = 'S' // 单字分词
+)
+
+// Tags 对文本分词，并返回每个字符在分词中的位置标注（BEMS）
+//
+// 返回值与文本中的字符一一对应，比如"中国有十三亿人口"返回"BESBMEBE"。停用词同样
+// 标注；空格等不属于任何分词的字符标注为TagSingle。设置了Unicode规范化时返回值
+// 对应规范化后文本中的字符。
+func (seg *Segmenter) Tags(bytes []byte) []rune {
+	text := seg.normalize(bytes)
+	tags := make([]rune, 0, utf8.RuneCount(text))
+	position := 0
+	for _, segment := range seg.appendAllSegments(nil, text, false, true) {
+		// 不属于任何分词的字符
+		tags = appendSingleTags(tags, text[position:segment.start])
+
+		numRunes := utf8.RuneCount(text[segment.start:segment.end])
+		if numRunes == 1 {
+			tags = append(tags, TagSingle)
+		} else {
+			tags = append(tags, TagBegin)
+			for i := 2; i < numRunes; i++ {
+				tags = append(tags, TagMiddle)
+			}
+			tags = append(tags, TagEnd)
+		}
+		position = segment.end
+	}
+	return appendSingleTags(tags, text[position:])
+}
+
+// 为text中的每个字符添加TagSingle
+func appendSingleTags(tags []rune, text []byte) []rune {
+	for i := utf8.RuneCount(text); i > 0; i-- {
+		tags = append(tags, TagSingle)
+	}
+	return tags
+}
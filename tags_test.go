@@ -0,0 +1,17 @@
+package sego
+
+import "testing"
+
+func TestTags(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	expect(t, "BESBMEBE", string(seg.Tags([]byte("中国有十三亿人口"))))
+	expect(t, "BMMMESBMMMESBE", string(seg.Tags([]byte("Hello World 中国"))))
+	expect(t, "SSBES", string(seg.Tags([]byte("\t 人口！"))))
+	expect(t, "", string(seg.Tags(nil)))
+
+	// 停用词同样标注
+	seg.SetStopWords([]string{"有"})
+	expect(t, "BESBMEBE", string(seg.Tags([]byte("中国有十三亿人口"))))
+}
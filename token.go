@@ -1,6 +1,9 @@
 package sego
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 // Text 字串类型，可以用来表达
 //	1. 一个字元，比如"中"又如"国", 英文的一个字元是一个词
@@ -13,22 +16,44 @@ type Token struct {
 	// 分词的字串，这实际上是个字元数组
 	text []Text
 
-	// 分词在语料库中的词频
+	// 分词在语料库中的词频，词典中为浮点权重时是权重的整数部分
 	frequency int
 
-	// log2(总词频/该分词词频)，这相当于log2(1/p(分词))，用作动态规划中
+	// 分词的权重，整数词频的分词权重即为词频
+	weight float64
+
+	// log2(总权重/该分词权重)，这相当于log2(1/p(分词))，用作动态规划中
 	// 该分词的路径长度。求解prod(p(分词))的最大值相当于求解
 	// sum(distance(分词))的最小值，这就是“最短路径”的来历。
 	distance float32
 
-	// 词性标注
+	// 按distanceScale量化后的路径值，见SetIntegerDistance
+	intDistance int32
+
+	// 词性标注，有多个词性时为第一个
 	pos string
 
+	// 全部词性标注，只有一个词性时为nil
+	posList []string
+
 	// 该分词文本的进一步分词划分，见Segments函数注释。
 	segments []*Segment
 
 	// 该分词的同义词
 	synonyms []*Token
+
+	// 是否为词典中的分词，分词时为未登录字元补加的伪分词为false
+	inDictionary bool
+
+	// 分词所在词典文件的序号，从0开始，序号小的优先。动态规划中两条路径相等时
+	// 选择优先的分词，用AddWord添加和叠加词典中的分词为0
+	priority int
+
+	// 分词所在的词典文件，见Source
+	source string
+
+	// 分词所属的词组，见Segmenter.SetEnabledGroups
+	group string
 }
 
 // Text 返回分词文本
@@ -36,16 +61,91 @@ func (token *Token) Text() string {
 	return textSliceToString(token.text)
 }
 
+// RuneLen 返回分词各字元的Unicode字符总数，而不是字节数
+//
+// Text()在英文字元之间补加的空格不计算在内，比如"hello world"的RuneLen为10。
+func (token *Token) RuneLen() (length int) {
+	for _, word := range token.text {
+		length += utf8.RuneCount(word)
+	}
+	return
+}
+
 // Frequency 返回分词在语料库中的词频
 func (token *Token) Frequency() int {
 	return token.frequency
 }
 
+// Weight 返回分词的权重，整数词频的分词权重即为词频
+func (token *Token) Weight() float64 {
+	return token.weight
+}
+
+// Distance 返回分词的路径值，即log2(总权重/该分词权重)，见Token结构体的注释
+//
+// 路径值在LoadDictionary或者Rebuild时计算，未登录字元的伪分词为SetUnknownDistance
+// 设置的距离。
+func (token *Token) Distance() float32 {
+	return token.distance
+}
+
+// Priority 返回分词所在词典文件的序号，从0开始，序号小的优先
+//
+// 用AddWord添加、叠加词典中的分词以及未登录字元的伪分词为0。
+func (token *Token) Priority() int {
+	return token.priority
+}
+
+// Words 返回分词的字元，比如"hello world"有两个字元"hello"和"world"
+//
+// 返回的是分词内部的数组，不能修改。
+func (token *Token) Words() []Text {
+	return token.text
+}
+
 // Pos 返回分词词性标注
 func (token *Token) Pos() string {
 	return token.pos
 }
 
+// PosList 返回分词的全部词性标注，第一个与Pos()相同，没有词性时返回nil
+func (token *Token) PosList() []string {
+	if token.posList != nil {
+		return token.posList
+	}
+	if token.pos == "" {
+		return nil
+	}
+	return []string{token.pos}
+}
+
+// Group 返回分词在词典中标记的词组名，没有标记时为空字符串，见Segmenter.SetEnabledGroups
+//
+// 由子分词的同义词组合出的同义词返回原分词的词组。
+func (token *Token) Group() string {
+	return token.group
+}
+
+// Source 返回分词所在的词典文件名，即LoadDictionary参数中的一个文件
+//
+// 由子分词的同义词组合出的同义词返回原分词所在的文件。用AddWord添加、叠加词典中的
+// 分词以及未登录字元的伪分词返回空字符串。
+func (token *Token) Source() string {
+	return token.source
+}
+
+// InDictionary 返回该分词是否来自词典，未登录字元的伪分词返回false
+func (token *Token) InDictionary() bool {
+	return token.inDictionary
+}
+
+// IsStop 返回该分词是否为词典中标注的停用词（词性为"__STOP__"）
+//
+// SetStopWords设置的停用词不会改变分词本身，请使用Segment.IsStop判断。
+func (token *Token) IsStop() bool {
+	return token.pos == "__STOP__"
+}
+
 // Segments 该分词文本的进一步分词划分，比如"中华人民共和国中央人民政府"这个分词
 // 有两个子分词"中华人民共和国"和"中央人民政府"。子分词也可以进一步有子分词
 // 形成一个树结构，遍历这个树就可以得到该分词的所有细致分词划分，这主要
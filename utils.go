@@ -2,7 +2,9 @@ package sego
 
 import (
 	"bytes"
-	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -16,22 +18,67 @@ import (
 //      "中华/nz 人民/n 共和/nz 共和国/ns 人民共和国/nt 中华人民共和国/ns "
 //
 // 搜索模式主要用于给搜索引擎提供尽可能多的关键字，详情请见Token结构体的注释。
-func SegmentsToString(segs []Segment) (output string) {
+func SegmentsToString(segs []Segment) string {
+	var output strings.Builder
+	output.Grow(segmentsStringSize(segs))
 	for _, seg := range segs {
-		output += fmt.Sprintf(
-			"%s/%s ", textSliceToString(seg.token.text), seg.token.pos)
+		writeToken(&output, seg.token)
+	}
+	return output.String()
+}
+
+// 估计SegmentsToString输出的字节数：每个分词的文本、词性以及"/"和空格
+func segmentsStringSize(segs []Segment) (size int) {
+	for _, seg := range segs {
+		size += textSliceByteLength(seg.token.text) + len(seg.token.text) + len(seg.token.pos) + 1
 	}
 	return
 }
 
-func tokenToString(token *Token) (output string) {
+// 按"文本/词性 "的格式输出一个分词
+func writeToken(output *strings.Builder, token *Token) {
+	writeJoined(output, token.text)
+	output.WriteByte('/')
+	output.WriteString(token.pos)
+	output.WriteByte(' ')
+}
+
+// SegmentsToText 按分词的起止位置从原文本src中还原出分词覆盖的文本
+//
+// 与Join不同，还原的文本不会补加空格：分词之间原有的空白、被删除的停用词等按原样保留，
+// 因此对Segment的结果调用时，除去第一个分词之前和最后一个分词之后的空白，得到的就是
+// 原文本，可以用于检验分词的起止位置。相互重叠的分词只输出未输出过的部分。src必须是
+// 分词时使用的文本。
+func SegmentsToText(segs []Segment, src []byte) string {
+	var output strings.Builder
+	last := 0
+	for i, seg := range segs {
+		start := seg.start
+		if i > 0 {
+			start = last
+		}
+		if seg.end > start {
+			output.Write(src[start:seg.end])
+			last = seg.end
+		}
+	}
+	return output.String()
+}
+
+func tokenToString(token *Token) string {
+	var output strings.Builder
+	writeTokenTree(&output, token)
+	return output.String()
+}
+
+// 按tokenToString的格式输出分词及其所有子分词
+func writeTokenTree(output *strings.Builder, token *Token) {
 	for _, s := range token.segments {
 		if s != nil {
-			output += tokenToString(s.token)
+			writeTokenTree(output, s.token)
 		}
 	}
-	output += fmt.Sprintf("%s/%s ", textSliceToString(token.text), token.pos)
-	return
+	writeToken(output, token)
 }
 
 // SegmentsToSlice 输出分词结果到一个字符串slice
@@ -43,11 +90,15 @@ func tokenToString(token *Token) (output string) {
 //      "[中华 人民 共和 共和国 人民共和国 中华人民共和国]"
 //
 // 搜索模式主要用于给搜索引擎提供尽可能多的关键字，详情请见Token结构体的注释。
-func SegmentsToSlice(segs []Segment) (output []string) {
-	for _, seg := range segs {
-		output = append(output, seg.token.Text())
+func SegmentsToSlice(segs []Segment) []string {
+	if len(segs) == 0 {
+		return nil
 	}
-	return
+	output := make([]string, len(segs))
+	for i, seg := range segs {
+		output[i] = seg.token.Text()
+	}
+	return output
 }
 
 func tokenToSlice(token *Token) (output []string) {
@@ -58,28 +109,133 @@ func tokenToSlice(token *Token) (output []string) {
 	return output
 }
 
+// GroupByPos 按词性对分词分组，键为分词的词性（见Token.Pos），每组内保持分词原有的顺序
+func GroupByPos(segs []Segment) map[string][]Segment {
+	groups := make(map[string][]Segment)
+	for _, seg := range segs {
+		pos := seg.token.Pos()
+		groups[pos] = append(groups[pos], seg)
+	}
+	return groups
+}
+
+// EachWithContext 依次对每个分词调用fn，同时传入前一个和后一个分词，没有时为nil
+//
+// 三个参数都指向segs中的元素，fn中对cur的修改会写回segs。适合需要查看相邻分词的
+// 规则，比如根据前后分词调整词性。
+func EachWithContext(segs []Segment, fn func(prev, cur, next *Segment)) {
+	for i := range segs {
+		var prev, next *Segment
+		if i > 0 {
+			prev = &segs[i-1]
+		}
+		if i+1 < len(segs) {
+			next = &segs[i+1]
+		}
+		fn(prev, &segs[i], next)
+	}
+}
+
 // SegmentsSpread 分词扩展，从一组分词中，扩展出全部子分词，同义词，以及同义词的子分词
 func SegmentsSpread(segs []Segment) (output []Segment) {
+	return SpreadIf(segs, nil)
+}
+
+// SpreadIf 分词扩展，与SegmentsSpread相同，但只对expand返回true的分词扩展子分词
+//
+// 子分词本身同样由expand决定是否继续扩展，同义词总是会被扩展出来。expand为nil时
+// 扩展全部分词。比如只对长度大于2的名词扩展子分词，以平衡索引大小和召回率：
+//	SpreadIf(segs, func(t *Token) bool {
+//		return len(t.Text()) > 6 && strings.HasPrefix(t.Pos(), "n")
+//	})
+func SpreadIf(segs []Segment, expand func(*Token) bool) (output []Segment) {
+	return spread(segs, spreadOptions{expand: expand, maxSynonyms: -1})
+}
+
+// SpreadWithoutSingleChars 分词扩展，与SegmentsSpread相同，但不扩展出单字的子分词
+//
+// 比如"中华"不再扩展出"中"和"华"，多字的子分词照常扩展。单字本身作为分词时仍然保留。
+// 单字子分词对搜索索引的精度帮助很小，去掉后可以明显减小索引。
+func SpreadWithoutSingleChars(segs []Segment) []Segment {
+	return spread(segs, spreadOptions{maxSynonyms: -1, skipSingleChars: true})
+}
+
+// SpreadTopSynonyms 分词扩展，与SegmentsSpread相同，但每个分词最多扩展出k个同义词
+//
+// 同义词按权重（见Token.Weight）从高到低排列，权重相同时保持词典中的顺序，只保留
+// 前k个；k小于零时保留全部同义词。子分词的同义词同样如此。适合查询扩展时只取
+// 常用的同义词，不被罕见的同义词淹没。
+func SpreadTopSynonyms(segs []Segment, k int) []Segment {
+	if k < 0 {
+		k = math.MaxInt32
+	}
+	return spread(segs, spreadOptions{maxSynonyms: k})
+}
+
+// 分词扩展的选项
+type spreadOptions struct {
+	// 只对返回true的分词扩展子分词，为nil时扩展全部分词，见SpreadIf
+	expand func(*Token) bool
+
+	// 每个分词最多扩展出的同义词数，见SpreadTopSynonyms；小于零时按词典中的顺序
+	// 扩展全部同义词
+	maxSynonyms int
+
+	// 不扩展出单字的子分词，见SpreadWithoutSingleChars
+	skipSingleChars bool
+}
+
+// 分词扩展，见SegmentsSpread
+func spread(segs []Segment, options spreadOptions) []Segment {
+	// 没有分词时同样返回空slice而不是nil，与Segment一致
+	output := make([]Segment, 0, len(segs))
 	for _, s := range segs {
 		// 子分词
-		var sub []Segment
-		for _, ss := range s.token.segments {
-			sub = append(sub, *ss)
+		if options.expand == nil || options.expand(s.token) {
+			var sub []Segment
+			for _, ss := range s.token.segments {
+				if options.skipSingleChars && isSingleChar(ss.token) {
+					continue
+				}
+				sub = append(sub, *ss)
+			}
+			output = append(output, spread(sub, options)...)
 		}
-		output = append(output, SegmentsSpread(sub)...)
 
 		// 同义词
-		for _, t := range s.token.synonyms {
+		synonyms := s.token.synonyms
+		if options.maxSynonyms >= 0 {
+			synonyms = topSynonyms(synonyms, options.maxSynonyms)
+		}
+		for _, t := range synonyms {
 			output = append(output, Segment{
-				start: s.start,
-				end:   s.end,
-				token: t,
+				start:      s.start,
+				end:        s.end,
+				token:      t,
+				spaceAfter: s.spaceAfter,
 			})
 		}
 
 		output = append(output, s)
 	}
-	return
+	return output
+}
+
+// 分词是否只有一个字符
+func isSingleChar(token *Token) bool {
+	return len(token.text) == 1 && utf8.RuneCount(token.text[0]) == 1
+}
+
+// 返回权重最高的k个同义词，按权重从高到低排列，synonyms不变
+func topSynonyms(synonyms []*Token, k int) []*Token {
+	sorted := append([]*Token{}, synonyms...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].weight > sorted[j].weight
+	})
+	if len(sorted) > k {
+		sorted = sorted[:k]
+	}
+	return sorted
 }
 
 // 将多个字元拼接一个字符串输出
@@ -89,18 +245,25 @@ func textSliceToString(text []Text) string {
 
 // Join 把字元slice拼接为字符串
 func Join(a []Text) string {
-	b := ""
+	if len(a) == 1 {
+		return string(a[0])
+	}
+	var b strings.Builder
+	b.Grow(textSliceByteLength(a) + len(a))
+	writeJoined(&b, a)
+	return b.String()
+}
 
+// 按Join的方式把字元拼接后写入b
+func writeJoined(b *strings.Builder, a []Text) {
 	for i, text := range a {
-		b += string(text)
+		b.Write(text)
 
 		r, size := utf8.DecodeRune(text)
 		if i != len(a)-1 && size <= 2 && (unicode.IsLetter(r) || unicode.IsNumber(r)) {
-			b += " "
+			b.WriteByte(' ')
 		}
 	}
-
-	return b
 }
 
 // 返回多个字元的字节总长度
@@ -2,6 +2,7 @@ package sego
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/issue9/assert"
@@ -140,3 +141,128 @@ func Test_Token_Split(t *testing.T) {
 		}
 	}
 }
+
+func Test_GroupByPos(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	groups := GroupByPos(seg.Segment([]byte("中国人口有人口，有十三亿")))
+	assert.Equal(t, 4, len(groups))
+	assert.Equal(t, "人口/p12 人口/p12 ", SegmentsToString(groups["p12"]))
+	assert.Equal(t, "有/p3 有/p3 ", SegmentsToString(groups["p3"]))
+	assert.Equal(t, "中国/ 十三亿/ ", SegmentsToString(groups[""]))
+	assert.Equal(t, "，/x ", SegmentsToString(groups["x"]))
+	assert.Equal(t, 0, len(GroupByPos(nil)))
+}
+
+func Test_SpreadTopSynonyms(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	overlay, _ := seg.Overlay(strings.NewReader("汽车 8 n|轿车 32 n|车子 2 n|座驾 32 n\n"))
+
+	segs := overlay.Segment([]byte("汽车"))
+	assert.Equal(t, "汽/x 车/x 轿车/n 车子/n 座驾/n 汽车/n ", SegmentsToString(SegmentsSpread(segs)))
+	assert.Equal(t, "汽/x 车/x 轿车/n 座驾/n 汽车/n ", SegmentsToString(SpreadTopSynonyms(segs, 2)))
+	assert.Equal(t, "汽/x 车/x 汽车/n ", SegmentsToString(SpreadTopSynonyms(segs, 0)))
+	assert.Equal(t, "汽/x 车/x 轿车/n 座驾/n 车子/n 汽车/n ", SegmentsToString(SpreadTopSynonyms(segs, -1)))
+
+	// 同义词的顺序不受影响
+	assert.Equal(t, "汽/x 车/x 轿车/n 车子/n 座驾/n 汽车/n ", SegmentsToString(SegmentsSpread(segs)))
+}
+
+func Test_EachWithContext(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	var contexts []string
+	text := func(s *Segment) string {
+		if s == nil {
+			return "nil"
+		}
+		return s.token.Text()
+	}
+	EachWithContext(seg.Segment([]byte("中国有十三亿人口")), func(prev, cur, next *Segment) {
+		contexts = append(contexts, text(prev)+"<"+text(cur)+">"+text(next))
+	})
+	assert.Equal(t, "nil<中国>有 中国<有>十三亿 有<十三亿>人口 十三亿<人口>nil", strings.Join(contexts, " "))
+
+	contexts = nil
+	EachWithContext(seg.Segment([]byte("中国")), func(prev, cur, next *Segment) {
+		contexts = append(contexts, text(prev)+"<"+text(cur)+">"+text(next))
+	})
+	assert.Equal(t, "nil<中国>nil", strings.Join(contexts, " "))
+
+	called := false
+	EachWithContext(nil, func(prev, cur, next *Segment) { called = true })
+	assert.False(t, called)
+}
+
+func Test_SpreadWithoutSingleChars(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	segs := seg.Segment([]byte("中国有十三亿人口"))
+	assert.Equal(t, "中/p1 国/p2 中国/ 有/p3 十/x 三/ 十三/p10 亿/p5 十三亿/ 人/p6 口/p7 人口/p12 ",
+		SegmentsToString(SegmentsSpread(segs)))
+	assert.Equal(t, "中国/ 有/p3 十三/p10 十三亿/ 人口/p12 ", SegmentsToString(SpreadWithoutSingleChars(segs)))
+
+	// 全分词时同样生效
+	seg.SetSpreadSingleChars(false)
+	assert.Equal(t, "中国/ 有/p3 十三/p10 十三亿/ 人口/p12 ", SegmentsToString(seg.FullSegment([]byte("中国有十三亿人口"))))
+	_, search := seg.SegmentBoth([]byte("中国有十三亿人口"))
+	assert.Equal(t, "中国/ 有/p3 十三/p10 十三亿/ 人口/p12 ", SegmentsToString(search))
+	overlay, _ := seg.Overlay(strings.NewReader(""))
+	assert.Equal(t, "人口/p12 ", SegmentsToString(overlay.FullSegment([]byte("人口"))))
+}
+
+func Test_SegmentsToText(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+	seg.SetStopWords([]string{"有"})
+
+	for _, text := range []string{"中国有十三亿人口", "hello  world，中国有\t人口", "Hello World"} {
+		assert.Equal(t, text, SegmentsToText(seg.Segment([]byte(text)), []byte(text)))
+	}
+
+	// 首尾的空白不在任何分词中
+	text := []byte("  人口 ")
+	assert.Equal(t, "人口", SegmentsToText(seg.Segment(text), text))
+	assert.Equal(t, "", SegmentsToText(nil, text))
+
+	// 重叠的分词只输出一次
+	segs := []Segment{{start: 0, end: 6}, {start: 3, end: 9}, {start: 3, end: 6}}
+	assert.Equal(t, "中国有", SegmentsToText(segs, []byte("中国有")))
+}
+
+func Test_SegmentsToString(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt,testdata/test_dict3.txt")
+
+	// 与逐个分词按"文本/词性 "格式拼接的结果相同
+	segs := seg.FullSegment([]byte("中国有十三亿人口，hello world 2024"))
+	expected := ""
+	var slice []string
+	for _, s := range segs {
+		expected += fmt.Sprintf("%s/%s ", s.token.Text(), s.token.pos)
+		slice = append(slice, s.token.Text())
+	}
+	assert.Equal(t, expected, SegmentsToString(segs))
+	assert.Equal(t, slice, SegmentsToSlice(segs))
+	assert.Equal(t, "", SegmentsToString(nil))
+	assert.Nil(t, SegmentsToSlice(nil))
+
+	token := seg.Segment([]byte("十三亿"))[0].token
+	assert.Equal(t, "十/x 三/ 十三/p10 亿/p5 十三亿/ ", tokenToString(token))
+}
+
+func Benchmark_SegmentsToString(b *testing.B) {
+	lines := loadBenchmarkLines(b)
+	var segs []Segment
+	for _, line := range lines {
+		segs = append(segs, prodSeg.Segment(line)...)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SegmentsToString(segs)
+	}
+}